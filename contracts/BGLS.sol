@@ -99,29 +99,44 @@ contract BGLS {
     uint pminus = 21888242871839275222246405745257275088696311157297823662689037894645226208582;
     uint pplus = 21888242871839275222246405745257275088696311157297823662689037894645226208584;
 
-    //    function hashToG1(uint[] memory b) internal returns (G1 memory) {
-    //        uint x = 0;
-    //        G1 memory res;
-    //        while (true) {
-    //            uint hx = uint(keccak256(abi.encodePacked(b, x))) % prime;
-    //            uint px = (modPow(hx, 3, prime) + 3);
-    //             y^2 = x^3 % p + 3
-    //            if (modPow(px, pminus / 2, prime) == 1) {// determine if px is a quadratic residue, === 1 means yes
-    //                 refer to https://mathworld.wolfram.com/QuadraticResidue.html
-    //                 prime is a special form of 4k+3,
-    //                 where k = 5472060717959818805561601436314318772174077789324455915672259473661306552145
-    //                 then x of x^2 = q (mod p) can be solved with x = q^(k+1) mod p, where k = (p - 3) / 4, k + 1 = (p + 1) / 4
-    //                uint py = modPow(px, pplus / 4, prime);
-    //
-    //                res = uint(keccak256(abi.encodePacked(b, uint(255)))) % 2 == 0 ? G1(hx, py) : G1(hx, prime - py);
-    //                break;
-    //            }
-    //            x++;
-    //        }
-    //        return res;
-    //    }
+    // try-and-increment: x = keccak256(message || counter) mod p for counter = 0, 1, ... until
+    // x^3 + 3 is a quadratic residue, refer to https://mathworld.wolfram.com/QuadraticResidue.html
+    // prime is a special form of 4k+3, so y = (x^3 + 3)^((p + 1) / 4), and the root whose parity
+    // matches the top bit of the hash is taken.
+    // matches hashToG1TryAndIncrement in test/blsbn254.ts
+    function hashToG1TryAndIncrement(bytes memory message) public returns (G1 memory) {
+        for (uint counter = 0; counter < 256; counter++) {
+            uint h = uint(keccak256(abi.encodePacked(message, counter)));
+            uint x = h % prime;
+            uint px = addmod(mulmod(mulmod(x, x, prime), x, prime), 3, prime);
+            if (modPow(px, pminus / 2, prime) == 1) {
+                uint y = modPow(px, pplus / 4, prime);
+                if ((h >> 255) != (y & 1)) y = prime - y;
+                return G1(x, y);
+            }
+        }
+        revert('hash to G1 failed');
+    }
 
     function checkSignature(bytes memory message, G1 memory sig, G2 memory aggKey) public returns (bool) {
         return pairingCheck(sig, g2, hashToG1(message), aggKey);
     }
+
+    // len(dst) || dst || message, so that a signature over a message for one purpose (e.g. an
+    // epoch change under "MAP_BLS_EPOCH_V1") does not check as one for another (a block seal
+    // under "MAP_BLS_SIG_V1", which LightNode checks committed seals with).
+    // tagged messages are hashed with try-and-increment: hashToG1 gives points of known discrete
+    // log, which would let anyone turn a signature under one tag into one under another
+    function domainMessage(bytes memory dst, bytes memory message) internal pure returns (bytes memory) {
+        require(dst.length > 0 && dst.length < 256, 'bad domain separation tag');
+        return abi.encodePacked(uint8(dst.length), dst, message);
+    }
+
+    function hashToG1WithDST(bytes memory dst, bytes memory message) public returns (G1 memory) {
+        return hashToG1TryAndIncrement(domainMessage(dst, message));
+    }
+
+    function checkSignatureWithDST(bytes memory dst, bytes memory message, G1 memory sig, G2 memory aggKey) public returns (bool) {
+        return pairingCheck(sig, g2, hashToG1WithDST(dst, message), aggKey);
+    }
 }
@@ -0,0 +1,46 @@
+// SPDX-License-Identifier: UNLICENSED
+pragma solidity >0.8.0;
+
+import "./BGLS.sol";
+import "./BN256G1.sol";
+import "./BN256G2.sol";
+
+// byte encodings of keys and signatures as helper/bls in atlas, inherited from celo, writes them:
+//
+// signature (G1) = x || y, 64 bytes
+// public key (G2) = xi || xr || yi || yr || flag, 129 bytes
+//
+// coordinates are 32 byte big endian. the flag byte must be zero, 128 byte keys without it are
+// accepted too, and encodeG2 writes it as zero. like LightNode, decoding rejects coordinates
+// outside the field so every point has one encoding.
+library BLSCodec {
+    uint internal constant G1_LENGTH = 64;
+    uint internal constant G2_LENGTH = 129;
+
+    function encodeG1(BGLS.G1 memory p) internal pure returns (bytes memory) {
+        return abi.encodePacked(p.x, p.y);
+    }
+
+    function decodeG1(bytes memory data) internal pure returns (BGLS.G1 memory p) {
+        require(data.length == G1_LENGTH, 'bad G1 encoding');
+        (p.x, p.y) = (word(data, 0), word(data, 1));
+        require(BN256G1.isOnCurveG1(p), 'bad G1 encoding');
+    }
+
+    function encodeG2(BGLS.G2 memory p) internal pure returns (bytes memory) {
+        return abi.encodePacked(p.xi, p.xr, p.yi, p.yr, uint8(0));
+    }
+
+    // also checks the key is in the order r subgroup, which the pairing precompile needs
+    function decodeG2(bytes memory data) internal view returns (BGLS.G2 memory p) {
+        require(data.length == G2_LENGTH - 1 || (data.length == G2_LENGTH && data[G2_LENGTH - 1] == 0), 'bad G2 encoding');
+        (p.xi, p.xr, p.yi, p.yr) = (word(data, 0), word(data, 1), word(data, 2), word(data, 3));
+        require(BN256G2.isInSubgroupG2(p), 'bad G2 encoding');
+    }
+
+    function word(bytes memory data, uint i) private pure returns (uint w) {
+        assembly {
+            w := mload(add(data, add(0x20, mul(i, 0x20))))
+        }
+    }
+}
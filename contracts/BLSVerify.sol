@@ -0,0 +1,76 @@
+// SPDX-License-Identifier: UNLICENSED
+pragma solidity >0.8.0;
+
+import "./BGLS.sol";
+import "./BN256G2.sol";
+
+// verifies an aggregated signature against the G2 public keys of the signers picked by a bitmap,
+// bit i of the bitmap is bit i % 8 of byte i / 8, the little endian bytes of EpochSnarkData.Bitmap.
+// the keys of the signers must be in the order r subgroup, which costs a pairing each.
+contract BLSVerify is BGLS {
+    uint public constant MAX_VALIDATORS = 256;
+    // same bound as WeightedMultiSig, 3 * the total weight of MAX_VALIDATORS cannot overflow
+    uint public constant MAX_WEIGHT = 2 ** 128;
+
+    struct WeightedValidator {
+        G2 pubkey;
+        uint weight; // voting power
+    }
+
+    function aggregatePubkeys(bytes memory bits, G2[] memory pubkeys) public view returns (G2 memory) {
+        checkBits(bits, pubkeys.length);
+
+        uint[6] memory acc;
+        for (uint i = 0; i < pubkeys.length; i++) {
+            if (!chkBit(bits, i)) continue;
+            require(BN256G2.isInSubgroupG2(pubkeys[i]), 'invalid G2 point');
+            acc = BN256G2.addJacobian(acc, BN256G2.fromAffine(pubkeys[i]));
+        }
+        return BN256G2.toAffine(acc);
+    }
+
+    // e(sig, g2) == e(H(m), \sum pubkeys[i] for bit i set)
+    function verifyAggregate(G1 memory sig, bytes memory bits, G2[] memory pubkeys, bytes memory message) public returns (bool) {
+        G2 memory aggPk = aggregatePubkeys(bits, pubkeys);
+        if (BN256G2.isInfinity(aggPk)) return false;
+
+        return checkSignature(message, sig, aggPk);
+    }
+
+    // the aggregated key of the signers picked by bits, with their weight and the weight of the whole set
+    function aggregateWeighted(bytes memory bits, WeightedValidator[] memory validators)
+        public view returns (G2 memory aggPk, uint signedWeight, uint totalWeight)
+    {
+        checkBits(bits, validators.length);
+
+        uint[6] memory acc;
+        for (uint i = 0; i < validators.length; i++) {
+            require(validators[i].weight <= MAX_WEIGHT, 'weight too large');
+            totalWeight += validators[i].weight;
+            if (!chkBit(bits, i)) continue;
+
+            require(BN256G2.isInSubgroupG2(validators[i].pubkey), 'invalid G2 point');
+            acc = BN256G2.addJacobian(acc, BN256G2.fromAffine(validators[i].pubkey));
+            signedWeight += validators[i].weight;
+        }
+        aggPk = BN256G2.toAffine(acc);
+    }
+
+    // verifyAggregate, and the signers hold more than 2/3 of the total weight
+    function verifyWeighted(
+        G1 memory sig, bytes memory bits, WeightedValidator[] memory validators, bytes memory message
+    ) public returns (bool) {
+        (G2 memory aggPk, uint signedWeight, uint totalWeight) = aggregateWeighted(bits, validators);
+        if (3 * signedWeight <= 2 * totalWeight) return false;
+        if (BN256G2.isInfinity(aggPk)) return false;
+
+        return checkSignature(message, sig, aggPk);
+    }
+
+    // one bit for each of n validators, the bits past the last one in its byte unset
+    function checkBits(bytes memory bits, uint n) private pure {
+        require(n <= MAX_VALIDATORS, 'too many validators');
+        require(bits.length == (n + 7) / 8, 'bad bits length');
+        require(n % 8 == 0 || uint8(bits[bits.length - 1]) >> (n % 8) == 0, 'unused bits set');
+    }
+}
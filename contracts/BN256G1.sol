@@ -0,0 +1,165 @@
+// SPDX-License-Identifier: UNLICENSED
+pragma solidity >0.8.0;
+
+import "./BGLS.sol";
+
+// G1 helpers on top of the BGLS precompile wrappers.
+// A compressed point is its x coordinate with the parity of y in the top bit,
+// bit 254 alone marks the point at infinity. p < 2^254 leaves both bits free.
+library BN256G1 {
+    uint internal constant FIELD_MODULUS = 0x30644e72e131a029b85045b68181585d97816a916871ca8d3c208c16d87cfd47;
+    uint internal constant SIGN_FLAG = 1 << 255;
+    uint internal constant INFINITY_FLAG = 1 << 254;
+
+    function isInfinity(BGLS.G1 memory a) internal pure returns (bool) {
+        return a.x == 0 && a.y == 0;
+    }
+
+    function add(BGLS.G1 memory a, BGLS.G1 memory b) internal view returns (BGLS.G1 memory r) {
+        if (isInfinity(a)) return b;
+        if (isInfinity(b)) return a;
+
+        uint[4] memory input = [a.x, a.y, b.x, b.y];
+        assembly {
+            if iszero(staticcall(gas(), 0x06, input, 0x80, r, 0x40)) {
+                revert(0, 0)
+            }
+        }
+    }
+
+    function mul(BGLS.G1 memory a, uint scalar) internal view returns (BGLS.G1 memory r) {
+        uint[3] memory input = [a.x, a.y, scalar];
+        assembly {
+            if iszero(staticcall(gas(), 0x07, input, 0x60, r, 0x40)) {
+                revert(0, 0)
+            }
+        }
+    }
+
+    // k * g1 of BGLS. there is no table of multiples for it, the 6000 gas mul precompile is
+    // cheaper than any sum of table entries in solidity
+    function mulGen(uint scalar) internal view returns (BGLS.G1 memory) {
+        return mul(BGLS.G1(1, 2), scalar);
+    }
+
+    // sum of scalars[i] * points[i], one mul and one add precompile call per point.
+    // at the EIP-1108 prices a mul costs 40 adds, and a bucket method with c-bit windows needs
+    // 256 / c adds per point before summing its buckets, so it loses to this loop for every n.
+    // it only paid off where mul was priced far above add, as before EIP-1108.
+    function msm(BGLS.G1[] memory points, uint[] memory scalars) internal view returns (BGLS.G1 memory acc) {
+        require(points.length == scalars.length, 'mismatch msm input');
+        for (uint i = 0; i < points.length; i++) acc = add(acc, mul(points[i], scalars[i]));
+    }
+
+    // y^2 = x^3 + 3 or infinity, G1 has cofactor 1 so this is also the subgroup check
+    function isOnCurveG1(BGLS.G1 memory a) internal pure returns (bool) {
+        if (a.x >= FIELD_MODULUS || a.y >= FIELD_MODULUS) return false;
+        if (isInfinity(a)) return true;
+        return mulmod(a.y, a.y, FIELD_MODULUS) == addmod(mulmod(mulmod(a.x, a.x, FIELD_MODULUS), a.x, FIELD_MODULUS), 3, FIELD_MODULUS);
+    }
+
+    function compress(BGLS.G1 memory a) internal pure returns (uint) {
+        if (isInfinity(a)) return INFINITY_FLAG;
+        require(a.x < FIELD_MODULUS && a.y < FIELD_MODULUS, 'invalid G1 point');
+        return a.y & 1 == 1 ? a.x | SIGN_FLAG : a.x;
+    }
+
+    // y^2 = x^3 + 3, reverts if x is not on the curve
+    function decompress(uint c) internal view returns (BGLS.G1 memory) {
+        if (c & INFINITY_FLAG != 0) return decompressInfinity(c);
+
+        uint x = c & ~SIGN_FLAG;
+        require(x < FIELD_MODULUS, 'invalid compressed G1 point');
+        (bool ok, uint y) = fpSqrt(curveRhs(x));
+        return withSign(ok, x, y, c);
+    }
+
+    // decompress without the modexp precompile, for chains that lack it or price it differently
+    function decompressPure(uint c) internal pure returns (BGLS.G1 memory) {
+        if (c & INFINITY_FLAG != 0) return decompressInfinity(c);
+
+        uint x = c & ~SIGN_FLAG;
+        require(x < FIELD_MODULUS, 'invalid compressed G1 point');
+        (bool ok, uint y) = sqrtMod(curveRhs(x), FIELD_MODULUS);
+        return withSign(ok, x, y, c);
+    }
+
+    function decompressInfinity(uint c) private pure returns (BGLS.G1 memory) {
+        require(c == INFINITY_FLAG, 'invalid compressed G1 point');
+        return BGLS.G1(0, 0);
+    }
+
+    function curveRhs(uint x) private pure returns (uint) {
+        return addmod(mulmod(mulmod(x, x, FIELD_MODULUS), x, FIELD_MODULUS), 3, FIELD_MODULUS);
+    }
+
+    // the root of the parity given by the sign flag of c
+    function withSign(bool ok, uint x, uint y, uint c) private pure returns (BGLS.G1 memory) {
+        require(ok, 'invalid compressed G1 point');
+        if ((y & 1 == 1) != (c & SIGN_FLAG != 0)) y = FIELD_MODULUS - y;
+        return BGLS.G1(x, y);
+    }
+
+    // p = 3 mod 4, so a^((p + 1) / 4) is a root whenever a has one
+    function fpSqrt(uint a) internal view returns (bool, uint) {
+        uint r = fpPow(a, (FIELD_MODULUS + 1) / 4);
+        return (mulmod(r, r, FIELD_MODULUS) == a, r);
+    }
+
+    function fpPow(uint base, uint exponent) internal view returns (uint) {
+        uint[6] memory input = [32, 32, 32, base, exponent, FIELD_MODULUS];
+        uint[1] memory result;
+        assembly {
+            if iszero(staticcall(gas(), 0x05, input, 0xc0, result, 0x20)) {
+                revert(0, 0)
+            }
+        }
+        return result[0];
+    }
+
+    // tonelli-shanks for an odd prime p: write p - 1 = q * 2^s with q odd, start from the root
+    // a^((q + 1) / 2) of a * a^q and fix the 2-power part of the error with powers of a
+    // non-residue. for p = 3 mod 4 (s = 1) the loop never runs and this is a^((p + 1) / 4)
+    function sqrtMod(uint a, uint p) internal pure returns (bool, uint) {
+        a %= p;
+        if (a == 0) return (true, 0);
+        if (powMod(a, (p - 1) / 2, p) != 1) return (false, 0);
+
+        uint q = p - 1;
+        uint s = 0;
+        while (q & 1 == 0) {
+            q >>= 1;
+            s++;
+        }
+        uint z = 2;
+        while (powMod(z, (p - 1) / 2, p) != p - 1) z++;
+
+        uint m = s;
+        uint c = powMod(z, q, p);
+        uint t = powMod(a, q, p);
+        uint r = powMod(a, (q + 1) / 2, p);
+        while (t != 1) {
+            // least i with t^(2^i) == 1, i < m since a is a residue
+            uint i = 0;
+            for (uint t2 = t; t2 != 1; t2 = mulmod(t2, t2, p)) i++;
+
+            uint b = c;
+            for (uint j = i + 1; j < m; j++) b = mulmod(b, b, p);
+            m = i;
+            c = mulmod(b, b, p);
+            t = mulmod(t, c, p);
+            r = mulmod(r, b, p);
+        }
+        return (true, r);
+    }
+
+    // square and multiply, what fpPow gets from the modexp precompile
+    function powMod(uint base, uint exponent, uint modulus) internal pure returns (uint result) {
+        result = 1 % modulus;
+        base %= modulus;
+        for (; exponent != 0; exponent >>= 1) {
+            if (exponent & 1 == 1) result = mulmod(result, base, modulus);
+            base = mulmod(base, base, modulus);
+        }
+    }
+}
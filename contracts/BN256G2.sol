@@ -0,0 +1,274 @@
+// SPDX-License-Identifier: UNLICENSED
+pragma solidity >0.8.0;
+
+import "./BGLS.sol";
+import "./BN256G1.sol";
+
+// G2 arithmetic on the BN256 twist in pure solidity, there is no precompile for it.
+// Fp2 = Fp[i] / (i^2 + 1), an element a0 + a1 * i maps to (xr, xi) / (yr, yi) of BGLS.G2.
+// Points are kept in jacobian coordinates [x0, x1, y0, y1, z0, z1] while computing,
+// (0, 0, 0, 0) is the affine encoding of the point at infinity, same as the pairing precompile.
+// A compressed point is (xr, xi) with the flags of BN256G1 in xr, the sign of y is the parity
+// of yr, or of yi when yr is zero.
+library BN256G2 {
+    uint internal constant FIELD_MODULUS = 0x30644e72e131a029b85045b68181585d97816a916871ca8d3c208c16d87cfd47;
+    // b / (9 + i), the twist is y^2 = x^3 + TWIST_B
+    uint internal constant TWIST_B0 = 0x2b149d40ceb8aaae81be18991be06ac3b5b4c5e559dbefa33267e6dc24a138e5;
+    uint internal constant TWIST_B1 = 0x009713b03af0fed4cd2cafadeed8fdf4a74fa084e52d1852e4a2bd0685c315d2;
+    // one pair costs 45000 + 34000 since EIP-1108 and 100000 + 80000 before, with room for the
+    // 1/64 of the gas a call keeps back
+    uint private constant PAIRING_GAS = 190000;
+
+    function addPoints(BGLS.G2 memory a, BGLS.G2 memory b) internal view returns (BGLS.G2 memory) {
+        return toAffine(addJacobian(fromAffine(a), fromAffine(b)));
+    }
+
+    function doublePoint(BGLS.G2 memory a) internal view returns (BGLS.G2 memory) {
+        return toAffine(doubleJacobian(fromAffine(a)));
+    }
+
+    // kP, double-and-add from the least significant bit
+    function scalarMultiply(BGLS.G2 memory a, uint scalar) internal view returns (BGLS.G2 memory) {
+        uint[6] memory acc;
+        uint[6] memory base = fromAffine(a);
+        while (scalar != 0) {
+            if (scalar & 1 == 1) acc = addJacobian(acc, base);
+            scalar >>= 1;
+            if (scalar != 0) base = doubleJacobian(base);
+        }
+        return toAffine(acc);
+    }
+
+    function isInfinity(BGLS.G2 memory a) internal pure returns (bool) {
+        return a.xr == 0 && a.xi == 0 && a.yr == 0 && a.yi == 0;
+    }
+
+    // y^2 = x^3 + TWIST_B or infinity. the twist has a large cofactor, see isInSubgroupG2
+    function isOnCurveG2(BGLS.G2 memory a) internal pure returns (bool) {
+        if (a.xr >= FIELD_MODULUS || a.xi >= FIELD_MODULUS || a.yr >= FIELD_MODULUS || a.yi >= FIELD_MODULUS) return false;
+        if (isInfinity(a)) return true;
+
+        (uint y0, uint y1) = fp2Mul(a.yr, a.yi, a.yr, a.yi);
+        (uint x0, uint x1) = fp2Mul(a.xr, a.xi, a.xr, a.xi);
+        (x0, x1) = fp2Mul(x0, x1, a.xr, a.xi);
+        (x0, x1) = fp2Add(x0, x1, TWIST_B0, TWIST_B1);
+        return x0 == y0 && x1 == y1;
+    }
+
+    // the pairing precompile rejects G2 inputs outside the order r subgroup (EIP-197),
+    // so pairing the point with infinity fails exactly when a is not in it. computing
+    // r * a here instead would cost millions of gas.
+    // a failing precompile burns all the gas it is given, so the call gets all there is and
+    // runs only with enough for one pair under either pricing: then a failure is the input's.
+    function isInSubgroupG2(BGLS.G2 memory a) internal view returns (bool) {
+        if (!isOnCurveG2(a)) return false;
+        require(gasleft() >= PAIRING_GAS, 'out of gas for subgroup check');
+
+        uint[6] memory input = [0, 0, a.xi, a.xr, a.yi, a.yr];
+        uint[1] memory result;
+        bool ok;
+        assembly {
+            ok := staticcall(gas(), 0x08, input, 0xc0, result, 0x20)
+        }
+        if (!ok) return false;
+        // any pairing with infinity is 1, anything else is not the precompile answering
+        require(result[0] == 1, 'bad pairing result');
+        return true;
+    }
+
+    function compress(BGLS.G2 memory a) internal pure returns (uint xr, uint xi) {
+        if (isInfinity(a)) return (BN256G1.INFINITY_FLAG, 0);
+        require(a.xr < FIELD_MODULUS && a.xi < FIELD_MODULUS && a.yr < FIELD_MODULUS && a.yi < FIELD_MODULUS, 'invalid G2 point');
+        return (isOdd(a.yr, a.yi) ? a.xr | BN256G1.SIGN_FLAG : a.xr, a.xi);
+    }
+
+    function decompress(uint xr, uint xi) internal view returns (BGLS.G2 memory) {
+        if (xr & BN256G1.INFINITY_FLAG != 0) {
+            require(xr == BN256G1.INFINITY_FLAG && xi == 0, 'invalid compressed G2 point');
+            return BGLS.G2(0, 0, 0, 0);
+        }
+
+        bool odd = xr & BN256G1.SIGN_FLAG != 0;
+        xr &= ~BN256G1.SIGN_FLAG;
+        require(xr < FIELD_MODULUS && xi < FIELD_MODULUS, 'invalid compressed G2 point');
+
+        (uint y0, uint y1) = fp2Mul(xr, xi, xr, xi);
+        (y0, y1) = fp2Mul(y0, y1, xr, xi);
+        (y0, y1) = fp2Add(y0, y1, TWIST_B0, TWIST_B1);
+        bool ok;
+        (ok, y0, y1) = fp2Sqrt(y0, y1);
+        require(ok, 'invalid compressed G2 point');
+
+        if (isOdd(y0, y1) != odd) (y0, y1) = fp2Sub(0, 0, y0, y1);
+        return BGLS.G2(xr, xi, y0, y1);
+    }
+
+    function isOdd(uint a0, uint a1) private pure returns (bool) {
+        return a0 != 0 ? a0 & 1 == 1 : a1 & 1 == 1;
+    }
+
+    function fromAffine(BGLS.G2 memory a) internal pure returns (uint[6] memory p) {
+        require(a.xr < FIELD_MODULUS && a.xi < FIELD_MODULUS && a.yr < FIELD_MODULUS && a.yi < FIELD_MODULUS, 'invalid G2 point');
+        if (isInfinity(a)) return p;
+        return [a.xr, a.xi, a.yr, a.yi, 1, 0];
+    }
+
+    function toAffine(uint[6] memory p) internal view returns (BGLS.G2 memory) {
+        if (p[4] == 0 && p[5] == 0) return BGLS.G2(0, 0, 0, 0);
+
+        (uint z0, uint z1) = fp2Inv(p[4], p[5]);
+        (uint z20, uint z21) = fp2Mul(z0, z1, z0, z1);
+        (uint x0, uint x1) = fp2Mul(p[0], p[1], z20, z21);
+        (z20, z21) = fp2Mul(z20, z21, z0, z1);
+        (uint y0, uint y1) = fp2Mul(p[2], p[3], z20, z21);
+        return BGLS.G2(x0, x1, y0, y1);
+    }
+
+    // add-2007-bl, https://hyperelliptic.org/EFD/g1p/auto-shortw-jacobian-0.html#addition-add-2007-bl
+    function addJacobian(uint[6] memory p1, uint[6] memory p2) internal pure returns (uint[6] memory r) {
+        if (p1[4] == 0 && p1[5] == 0) return p2;
+        if (p2[4] == 0 && p2[5] == 0) return p1;
+
+        // z1z1: 0-1, z2z2: 2-3, u1: 4-5, u2: 6-7, s1: 8-9, s2: 10-11
+        uint[12] memory t;
+        (t[0], t[1]) = fp2Mul(p1[4], p1[5], p1[4], p1[5]);
+        (t[2], t[3]) = fp2Mul(p2[4], p2[5], p2[4], p2[5]);
+        (t[4], t[5]) = fp2Mul(p1[0], p1[1], t[2], t[3]);
+        (t[6], t[7]) = fp2Mul(p2[0], p2[1], t[0], t[1]);
+        (t[8], t[9]) = fp2Mul(p1[2], p1[3], p2[4], p2[5]);
+        (t[8], t[9]) = fp2Mul(t[8], t[9], t[2], t[3]);
+        (t[10], t[11]) = fp2Mul(p2[2], p2[3], p1[4], p1[5]);
+        (t[10], t[11]) = fp2Mul(t[10], t[11], t[0], t[1]);
+
+        if (t[4] == t[6] && t[5] == t[7]) {
+            if (t[8] == t[10] && t[9] == t[11]) return doubleJacobian(p1);
+            return r; // P + (-P)
+        }
+
+        // h = u2 - u1, r = 2 * (s2 - s1)
+        (t[6], t[7]) = fp2Sub(t[6], t[7], t[4], t[5]);
+        (t[10], t[11]) = fp2Sub(t[10], t[11], t[8], t[9]);
+        (t[10], t[11]) = fp2Add(t[10], t[11], t[10], t[11]);
+
+        // z3 = 2 * z1 * z2 * h
+        (r[4], r[5]) = fp2Mul(p1[4], p1[5], p2[4], p2[5]);
+        (r[4], r[5]) = fp2Add(r[4], r[5], r[4], r[5]);
+        (r[4], r[5]) = fp2Mul(r[4], r[5], t[6], t[7]);
+
+        // i = (2 * h)^2, j = h * i, v = u1 * i
+        (t[0], t[1]) = fp2Add(t[6], t[7], t[6], t[7]);
+        (t[0], t[1]) = fp2Mul(t[0], t[1], t[0], t[1]);
+        (t[2], t[3]) = fp2Mul(t[6], t[7], t[0], t[1]);
+        (t[4], t[5]) = fp2Mul(t[4], t[5], t[0], t[1]);
+
+        // x3 = r^2 - j - 2 * v
+        (r[0], r[1]) = fp2Mul(t[10], t[11], t[10], t[11]);
+        (r[0], r[1]) = fp2Sub(r[0], r[1], t[2], t[3]);
+        (r[0], r[1]) = fp2Sub(r[0], r[1], t[4], t[5]);
+        (r[0], r[1]) = fp2Sub(r[0], r[1], t[4], t[5]);
+
+        // y3 = r * (v - x3) - 2 * s1 * j
+        (r[2], r[3]) = fp2Sub(t[4], t[5], r[0], r[1]);
+        (r[2], r[3]) = fp2Mul(t[10], t[11], r[2], r[3]);
+        (t[8], t[9]) = fp2Mul(t[8], t[9], t[2], t[3]);
+        (t[8], t[9]) = fp2Add(t[8], t[9], t[8], t[9]);
+        (r[2], r[3]) = fp2Sub(r[2], r[3], t[8], t[9]);
+    }
+
+    // dbl-2009-l, https://hyperelliptic.org/EFD/g1p/auto-shortw-jacobian-0.html#doubling-dbl-2009-l
+    function doubleJacobian(uint[6] memory p) internal pure returns (uint[6] memory r) {
+        if (p[4] == 0 && p[5] == 0) return p;
+
+        // a: 0-1, b: 2-3, c: 4-5, d: 6-7
+        uint[8] memory t;
+        (t[0], t[1]) = fp2Mul(p[0], p[1], p[0], p[1]);
+        (t[2], t[3]) = fp2Mul(p[2], p[3], p[2], p[3]);
+        (t[4], t[5]) = fp2Mul(t[2], t[3], t[2], t[3]);
+
+        // d = 2 * ((x + b)^2 - a - c)
+        (t[6], t[7]) = fp2Add(p[0], p[1], t[2], t[3]);
+        (t[6], t[7]) = fp2Mul(t[6], t[7], t[6], t[7]);
+        (t[6], t[7]) = fp2Sub(t[6], t[7], t[0], t[1]);
+        (t[6], t[7]) = fp2Sub(t[6], t[7], t[4], t[5]);
+        (t[6], t[7]) = fp2Add(t[6], t[7], t[6], t[7]);
+
+        // e = 3 * a, kept in 0-1
+        (t[2], t[3]) = fp2Add(t[0], t[1], t[0], t[1]);
+        (t[0], t[1]) = fp2Add(t[2], t[3], t[0], t[1]);
+
+        // z3 = 2 * y * z
+        (r[4], r[5]) = fp2Mul(p[2], p[3], p[4], p[5]);
+        (r[4], r[5]) = fp2Add(r[4], r[5], r[4], r[5]);
+
+        // x3 = e^2 - 2 * d
+        (r[0], r[1]) = fp2Mul(t[0], t[1], t[0], t[1]);
+        (r[0], r[1]) = fp2Sub(r[0], r[1], t[6], t[7]);
+        (r[0], r[1]) = fp2Sub(r[0], r[1], t[6], t[7]);
+
+        // y3 = e * (d - x3) - 8 * c
+        (r[2], r[3]) = fp2Sub(t[6], t[7], r[0], r[1]);
+        (r[2], r[3]) = fp2Mul(t[0], t[1], r[2], r[3]);
+        (t[4], t[5]) = fp2Add(t[4], t[5], t[4], t[5]);
+        (t[4], t[5]) = fp2Add(t[4], t[5], t[4], t[5]);
+        (t[4], t[5]) = fp2Add(t[4], t[5], t[4], t[5]);
+        (r[2], r[3]) = fp2Sub(r[2], r[3], t[4], t[5]);
+    }
+
+    function fp2Add(uint a0, uint a1, uint b0, uint b1) internal pure returns (uint, uint) {
+        return (addmod(a0, b0, FIELD_MODULUS), addmod(a1, b1, FIELD_MODULUS));
+    }
+
+    function fp2Sub(uint a0, uint a1, uint b0, uint b1) internal pure returns (uint, uint) {
+        return (addmod(a0, FIELD_MODULUS - b0, FIELD_MODULUS), addmod(a1, FIELD_MODULUS - b1, FIELD_MODULUS));
+    }
+
+    // (a0 + a1 * i) * (b0 + b1 * i) = (a0 * b0 - a1 * b1) + (a0 * b1 + a1 * b0) * i
+    function fp2Mul(uint a0, uint a1, uint b0, uint b1) internal pure returns (uint, uint) {
+        return (
+            addmod(mulmod(a0, b0, FIELD_MODULUS), FIELD_MODULUS - mulmod(a1, b1, FIELD_MODULUS), FIELD_MODULUS),
+            addmod(mulmod(a0, b1, FIELD_MODULUS), mulmod(a1, b0, FIELD_MODULUS), FIELD_MODULUS)
+        );
+    }
+
+    // 1 / (a0 + a1 * i) = (a0 - a1 * i) / (a0^2 + a1^2)
+    function fp2Inv(uint a0, uint a1) internal view returns (uint, uint) {
+        uint inv = fpInv(addmod(mulmod(a0, a0, FIELD_MODULUS), mulmod(a1, a1, FIELD_MODULUS), FIELD_MODULUS));
+        return (mulmod(a0, inv, FIELD_MODULUS), mulmod(FIELD_MODULUS - a1, inv, FIELD_MODULUS));
+    }
+
+    // with n = sqrt(a0^2 + a1^2): x0 = sqrt((a0 +- n) / 2), x1 = a1 / (2 * x0)
+    function fp2Sqrt(uint a0, uint a1) internal view returns (bool, uint, uint) {
+        bool ok;
+        uint x0;
+        if (a1 == 0) {
+            (ok, x0) = BN256G1.fpSqrt(a0);
+            if (ok) return (true, x0, 0);
+            (ok, x0) = BN256G1.fpSqrt(FIELD_MODULUS - a0);
+            return (ok, 0, x0);
+        }
+
+        uint n;
+        (ok, n) = BN256G1.fpSqrt(addmod(mulmod(a0, a0, FIELD_MODULUS), mulmod(a1, a1, FIELD_MODULUS), FIELD_MODULUS));
+        if (!ok) return (false, 0, 0);
+
+        uint half = (FIELD_MODULUS + 1) / 2;
+        (ok, x0) = BN256G1.fpSqrt(mulmod(addmod(a0, n, FIELD_MODULUS), half, FIELD_MODULUS));
+        if (!ok) {
+            (ok, x0) = BN256G1.fpSqrt(mulmod(addmod(a0, FIELD_MODULUS - n, FIELD_MODULUS), half, FIELD_MODULUS));
+            if (!ok) return (false, 0, 0);
+        }
+        return (true, x0, mulmod(a1, fpInv(addmod(x0, x0, FIELD_MODULUS)), FIELD_MODULUS));
+    }
+
+    // a^(p - 2) via the modexp precompile
+    function fpInv(uint a) internal view returns (uint) {
+        uint[6] memory input = [32, 32, 32, a, FIELD_MODULUS - 2, FIELD_MODULUS];
+        uint[1] memory result;
+        assembly {
+            if iszero(staticcall(gas(), 0x05, input, 0xc0, result, 0x20)) {
+                revert(0, 0)
+            }
+        }
+        return result[0];
+    }
+}
@@ -0,0 +1,33 @@
+// SPDX-License-Identifier: UNLICENSED
+pragma solidity >0.8.0;
+
+import "./BGLS.sol";
+
+// one call to the pairing precompile for many equations, it costs 45000 + 34000 per pair
+// (EIP-1108), so n signature checks as n calls of two pairs pay the base cost n times.
+library BN256Pairing {
+    // returns e(a[0], b[0]) * ... * e(a[n - 1], b[n - 1]) == 1
+    function checkBatch(BGLS.G1[] memory a, BGLS.G2[] memory b) internal view returns (bool) {
+        require(a.length == b.length, 'mismatch pairing input');
+        require(a.length > 0, 'empty pairing input');
+
+        uint[] memory input = new uint[](a.length * 6);
+        for (uint i = 0; i < a.length; i++) {
+            uint j = i * 6;
+            input[j] = a[i].x;
+            input[j + 1] = a[i].y;
+            input[j + 2] = b[i].xi;
+            input[j + 3] = b[i].xr;
+            input[j + 4] = b[i].yi;
+            input[j + 5] = b[i].yr;
+        }
+
+        uint[1] memory result;
+        assembly {
+            if iszero(staticcall(gas(), 0x08, add(input, 0x20), mul(mload(input), 0x20), result, 0x20)) {
+                revert(0, 0)
+            }
+        }
+        return result[0] == 1;
+    }
+}
@@ -0,0 +1,65 @@
+// SPDX-License-Identifier: UNLICENSED
+pragma solidity >0.8.0;
+
+// bitmaps of more than 256 validators, word i holds bits 256 * i to 256 * i + 255 and bit j of
+// a word is (word >> j) & 1, so a single word reads the same as the uint bitmaps of the seals.
+// bits past the end of the array read as clear.
+library Bitmap {
+    function isBitSet(uint[] memory bitmap, uint i) internal pure returns (bool) {
+        if (i / 256 >= bitmap.length) return false;
+        return (bitmap[i / 256] >> (i % 256)) & 1 == 1;
+    }
+
+    function countSetBits(uint x) internal pure returns (uint n) {
+        unchecked {
+            for (; x != 0; n++) x &= x - 1;
+        }
+    }
+
+    function countSetBits(uint[] memory bitmap) internal pure returns (uint n) {
+        for (uint i = 0; i < bitmap.length; i++) n += countSetBits(bitmap[i]);
+    }
+
+    // no bit at or above n is set, the check that a bitmap picks only from a set of n validators
+    function fitsIn(uint[] memory bitmap, uint n) internal pure returns (bool) {
+        for (uint i = n / 256; i < bitmap.length; i++) {
+            uint word = i == n / 256 ? bitmap[i] >> (n % 256) : bitmap[i];
+            if (word != 0) return false;
+        }
+        return true;
+    }
+
+    // the first set bit at or after from, 256 * bitmap.length when there is none:
+    //
+    //   for (uint i = nextSetBit(b, 0); i < 256 * b.length; i = nextSetBit(b, i + 1)) { ... }
+    function nextSetBit(uint[] memory bitmap, uint from) internal pure returns (uint) {
+        uint end = 256 * bitmap.length;
+        if (from >= end) return end;
+
+        uint w = from / 256;
+        uint word = bitmap[w] >> (from % 256) << (from % 256);
+        while (word == 0) {
+            if (++w == bitmap.length) return end;
+            word = bitmap[w];
+        }
+        return 256 * w + lowestBit(word);
+    }
+
+    // the indices of the set bits in increasing order
+    function setBits(uint[] memory bitmap) internal pure returns (uint[] memory indices) {
+        indices = new uint[](countSetBits(bitmap));
+        uint end = 256 * bitmap.length;
+        uint j = 0;
+        for (uint i = nextSetBit(bitmap, 0); i < end; i = nextSetBit(bitmap, i + 1)) indices[j++] = i;
+    }
+
+    // index of the lowest set bit of a non-zero word
+    function lowestBit(uint x) private pure returns (uint r) {
+        for (uint s = 128; s > 0; s >>= 1) {
+            if (x & ((1 << s) - 1) == 0) {
+                x >>= s;
+                r += s;
+            }
+        }
+    }
+}
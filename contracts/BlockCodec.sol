@@ -0,0 +1,73 @@
+// SPDX-License-Identifier: UNLICENSED
+pragma solidity >0.8.0;
+
+import "./RLPReader.sol";
+import "./HeaderCodec.sol";
+
+// decodes a whole atlas block as encoded by Block.EncodeRLP in test/testdata/block.go:
+//
+// block = rlp([Header, Txs, Randomness, EpochSnarkData])
+// randomness = rlp([Revealed, Committed])
+// epoch snark data = rlp([Bitmap, Signature])
+//
+// the transactions are skipped, only the header and the consensus fields are read out.
+// a nil Randomness or EpochSnarkData is encoded as an empty list, which Block.DecodeRLP
+// rejects, so it is rejected here too. blocks carry EmptyRandomness and EmptyEpochSnarkData
+// instead, two zero fields each.
+library BlockCodec {
+    using RLPReader for bytes;
+    using RLPReader for RLPReader.RLPItem;
+
+    struct Randomness {
+        bytes32 revealed;
+        bytes32 committed;
+    }
+
+    struct EpochSnarkData {
+        uint bitmap;
+        bytes signature;
+    }
+
+    struct Block {
+        HeaderCodec.Header header;
+        uint txCount;
+        Randomness randomness;
+        EpochSnarkData epochSnarkData;
+    }
+
+    function decode(bytes memory data) internal pure returns (Block memory b) {
+        RLPReader.RLPItem[] memory fields = fieldsOf(data);
+        b.header = HeaderCodec.decode(fields[0]);
+        b.txCount = fields[1].numItems();
+        b.randomness = decodeRandomness(fields[2]);
+        b.epochSnarkData = decodeEpochSnarkData(fields[3]);
+    }
+
+    // the rlp of the header, what HeaderCodec.decode and LightNode.submitHeader take
+    function headerRlp(bytes memory data) internal pure returns (bytes memory) {
+        return fieldsOf(data)[0].toRlpBytes();
+    }
+
+    function epochSnarkData(bytes memory data) internal pure returns (EpochSnarkData memory) {
+        return decodeEpochSnarkData(fieldsOf(data)[3]);
+    }
+
+    function fieldsOf(bytes memory data) private pure returns (RLPReader.RLPItem[] memory fields) {
+        fields = data.toRlpItem().toList();
+        require(fields.length == 4, 'bad block');
+    }
+
+    function decodeRandomness(RLPReader.RLPItem memory item) private pure returns (Randomness memory r) {
+        RLPReader.RLPItem[] memory fields = item.toList();
+        require(fields.length == 2, 'bad block randomness');
+        r.revealed = fields[0].toBytes32();
+        r.committed = fields[1].toBytes32();
+    }
+
+    function decodeEpochSnarkData(RLPReader.RLPItem memory item) private pure returns (EpochSnarkData memory e) {
+        RLPReader.RLPItem[] memory fields = item.toList();
+        require(fields.length == 2, 'bad epoch snark data');
+        e.bitmap = fields[0].toUint();
+        e.signature = fields[1].toBytes();
+    }
+}
@@ -0,0 +1,130 @@
+// SPDX-License-Identifier: UNLICENSED
+pragma solidity >0.8.0;
+
+import "./HeaderCodec.sol";
+
+// fixed layout binary encoding of the atlas Header, a cheaper alternative to its rlp in calldata.
+// fields are big endian and in the order of the rlp, the optional ones only present when their
+// flag is set, and the extra takes the rest of the data:
+//
+// flags               1
+// parentHash          32
+// coinbase            20
+// root                32
+// txHash              32
+// receiptHash         32
+// bloom               256, left out when it is empty and only then
+// number              8
+// gasLimit            8
+// gasUsed             8
+// time                8
+// mixDigest           32
+// nonce               8
+// baseFee             32
+// blobGasUsed         8
+// excessBlobGas       8
+// parentBeaconRoot    32
+// extra               rest
+//
+// a header decodes to the same HeaderCodec.Header as its rlp, so it hashes the same.
+// Header.MarshalCompact in test/testdata/block.go writes it.
+library CompactHeader {
+    uint internal constant HAS_BASE_FEE = 1;
+    uint internal constant HAS_BLOB_GAS = 2; // blobGasUsed and excessBlobGas
+    uint internal constant HAS_PARENT_BEACON_ROOT = 4;
+    uint internal constant EMPTY_BLOOM = 8;
+
+    uint internal constant MIN_LENGTH = 221; // the flags and the fields that are always present
+
+    function decode(bytes memory data) internal pure returns (HeaderCodec.Header memory h) {
+        require(data.length >= MIN_LENGTH, 'bad compact header');
+        uint flags = uint8(data[0]);
+        // the optional rlp fields are encoded up to the last one present, so one cannot be
+        // present without the ones before it
+        require(flags < 16, 'bad compact header');
+        require(flags & HAS_BLOB_GAS == 0 || flags & HAS_BASE_FEE != 0, 'bad compact header');
+        require(flags & HAS_PARENT_BEACON_ROOT == 0 || flags & HAS_BLOB_GAS != 0, 'bad compact header');
+        require(data.length >= length(flags), 'bad compact header');
+
+        uint at = 1;
+        h.parentHash = bytes32(word(data, at));
+        h.coinbase = address(uint160(word(data, at + 32) >> 96));
+        h.root = bytes32(word(data, at + 52));
+        h.txHash = bytes32(word(data, at + 84));
+        h.receiptHash = bytes32(word(data, at + 116));
+        at += 148;
+
+        if (flags & EMPTY_BLOOM != 0) {
+            h.bloom = new bytes(HeaderCodec.BLOOM_LENGTH);
+        } else {
+            h.bloom = slice(data, at, HeaderCodec.BLOOM_LENGTH);
+            // otherwise two encodings of the header would be two submissions
+            require(!isZero(h.bloom), 'bad compact header');
+            at += HeaderCodec.BLOOM_LENGTH;
+        }
+
+        h.number = word(data, at) >> 192;
+        h.gasLimit = word(data, at + 8) >> 192;
+        h.gasUsed = word(data, at + 16) >> 192;
+        h.time = word(data, at + 24) >> 192;
+        h.mixDigest = bytes32(word(data, at + 32));
+        h.nonce = bytes8(bytes32(word(data, at + 64)));
+        at += 72;
+
+        if (flags & HAS_BASE_FEE != 0) {
+            h.hasBaseFee = true;
+            h.baseFee = word(data, at);
+            at += 32;
+        }
+        if (flags & HAS_BLOB_GAS != 0) {
+            h.hasBlobGas = true;
+            h.blobGasUsed = word(data, at) >> 192;
+            h.excessBlobGas = word(data, at + 8) >> 192;
+            at += 16;
+        }
+        if (flags & HAS_PARENT_BEACON_ROOT != 0) {
+            h.hasParentBeaconRoot = true;
+            h.parentBeaconRoot = bytes32(word(data, at));
+            at += 32;
+        }
+
+        h.extra = slice(data, at, data.length - at);
+    }
+
+    // length of a header with flags and an empty extra
+    function length(uint flags) private pure returns (uint n) {
+        n = MIN_LENGTH;
+        if (flags & EMPTY_BLOOM == 0) n += HeaderCodec.BLOOM_LENGTH;
+        if (flags & HAS_BASE_FEE != 0) n += 32;
+        if (flags & HAS_BLOB_GAS != 0) n += 16;
+        if (flags & HAS_PARENT_BEACON_ROOT != 0) n += 32;
+    }
+
+    function isZero(bytes memory b) private pure returns (bool) {
+        for (uint i = 0; i < b.length; i += 32) {
+            if (word(b, i) != 0) return false;
+        }
+        return true;
+    }
+
+    // the 32 bytes at offset at, reads past the end of data are only ever shifted out
+    function word(bytes memory data, uint at) private pure returns (uint w) {
+        assembly {
+            w := mload(add(add(data, 0x20), at))
+        }
+    }
+
+    function slice(bytes memory data, uint at, uint len) private pure returns (bytes memory out) {
+        out = new bytes(len);
+        for (uint i = 0; i < len; i += 32) {
+            uint w = word(data, at + i);
+            assembly {
+                mstore(add(add(out, 0x20), i), w)
+            }
+        }
+        // the last word copies bytes past len into the padding of out, clear them
+        assembly {
+            mstore(add(add(out, 0x20), len), 0)
+        }
+    }
+}
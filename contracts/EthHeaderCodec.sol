@@ -0,0 +1,135 @@
+// SPDX-License-Identifier: UNLICENSED
+pragma solidity >0.8.0;
+
+import "./RLPReader.sol";
+import "./RLPEncode.sol";
+
+// rlp codec for the go-ethereum Header, EthHeader in test/testdata/eth_header.go. unlike the
+// atlas Header it has UncleHash and Difficulty, and no istanbul extra, so the hash is the
+// keccak of the rlp as it is
+library EthHeaderCodec {
+    using RLPReader for bytes;
+    using RLPReader for RLPReader.RLPItem;
+
+    struct EthHeader {
+        bytes32 parentHash;
+        bytes32 uncleHash;
+        address coinbase;
+        bytes32 root;
+        bytes32 txHash;
+        bytes32 receiptHash;
+        bytes bloom;
+        uint difficulty;
+        uint number;
+        uint gasLimit;
+        uint gasUsed;
+        uint time;
+        bytes extra;
+        bytes32 mixDigest;
+        bytes8 nonce;
+        // the fields below are `rlp:"optional"` as in HeaderCodec, each fork adds the next ones
+        bool hasBaseFee; // london, EIP-1559
+        uint baseFee;
+        bool hasWithdrawalsHash; // shanghai, EIP-4895
+        bytes32 withdrawalsHash;
+        bool hasBlobGas; // cancun, EIP-4844 and EIP-4788
+        uint blobGasUsed;
+        uint excessBlobGas;
+        bytes32 parentBeaconRoot;
+        bool hasRequestsHash; // prague, EIP-7685
+        bytes32 requestsHash;
+    }
+
+    uint internal constant BLOOM_LENGTH = 256;
+    // keccak(rlp([])), the UncleHash of every header since the merge
+    bytes32 internal constant EMPTY_UNCLE_HASH = 0x1dcc4de8dec75d7aab85b567b6ccd41ad312451b948a7413f0a142fd40d49347;
+
+    function fieldCount(EthHeader memory h) private pure returns (uint) {
+        if (h.hasRequestsHash) return 21;
+        if (h.hasBlobGas) return 20;
+        if (h.hasWithdrawalsHash) return 17;
+        if (h.hasBaseFee) return 16;
+        return 15;
+    }
+
+    function encode(EthHeader memory h) internal pure returns (bytes memory) {
+        bytes[] memory list = new bytes[](fieldCount(h));
+        list[0] = RLPEncode.encodeBytes32(h.parentHash);
+        list[1] = RLPEncode.encodeBytes32(h.uncleHash);
+        list[2] = RLPEncode.encodeAddress(h.coinbase);
+        list[3] = RLPEncode.encodeBytes32(h.root);
+        list[4] = RLPEncode.encodeBytes32(h.txHash);
+        list[5] = RLPEncode.encodeBytes32(h.receiptHash);
+        list[6] = RLPEncode.encodeBytes(h.bloom);
+        list[7] = RLPEncode.encodeUint(h.difficulty);
+        list[8] = RLPEncode.encodeUint(h.number);
+        list[9] = RLPEncode.encodeUint(h.gasLimit);
+        list[10] = RLPEncode.encodeUint(h.gasUsed);
+        list[11] = RLPEncode.encodeUint(h.time);
+        list[12] = RLPEncode.encodeBytes(h.extra);
+        list[13] = RLPEncode.encodeBytes32(h.mixDigest);
+        list[14] = RLPEncode.encodeBytes(abi.encodePacked(h.nonce));
+        if (list.length > 15) list[15] = RLPEncode.encodeUint(h.baseFee);
+        if (list.length > 16) list[16] = RLPEncode.encodeBytes32(h.withdrawalsHash);
+        if (list.length > 17) {
+            list[17] = RLPEncode.encodeUint(h.blobGasUsed);
+            list[18] = RLPEncode.encodeUint(h.excessBlobGas);
+            list[19] = RLPEncode.encodeBytes32(h.parentBeaconRoot);
+        }
+        if (list.length > 20) list[20] = RLPEncode.encodeBytes32(h.requestsHash);
+
+        return RLPEncode.encodeList(list);
+    }
+
+    function decode(bytes memory data) internal pure returns (EthHeader memory h) {
+        RLPReader.RLPItem[] memory fields = data.toRlpItem().toList();
+        // the cancun fields come together
+        require(fields.length >= 15 && fields.length <= 21 && fields.length != 18 && fields.length != 19, 'bad eth header');
+
+        h.parentHash = fields[0].toBytes32();
+        h.uncleHash = fields[1].toBytes32();
+        h.coinbase = fields[2].toAddress();
+        h.root = fields[3].toBytes32();
+        h.txHash = fields[4].toBytes32();
+        h.receiptHash = fields[5].toBytes32();
+        h.bloom = fields[6].toBytes();
+        require(h.bloom.length == BLOOM_LENGTH, 'bad header bloom');
+        h.difficulty = fields[7].toUint();
+        h.number = fields[8].toUint();
+        h.gasLimit = fields[9].toUint64();
+        h.gasUsed = fields[10].toUint64();
+        h.time = fields[11].toUint64();
+        h.extra = fields[12].toBytes();
+        h.mixDigest = fields[13].toBytes32();
+        require(fields[14].len == 9, 'bad header nonce');
+        h.nonce = fields[14].toBytes8();
+        if (fields.length > 15) {
+            h.hasBaseFee = true;
+            h.baseFee = fields[15].toUint();
+        }
+        if (fields.length > 16) {
+            h.hasWithdrawalsHash = true;
+            h.withdrawalsHash = fields[16].toBytes32();
+        }
+        if (fields.length > 17) {
+            h.hasBlobGas = true;
+            h.blobGasUsed = fields[17].toUint64();
+            h.excessBlobGas = fields[18].toUint64();
+            h.parentBeaconRoot = fields[19].toBytes32();
+        }
+        if (fields.length > 20) {
+            h.hasRequestsHash = true;
+            h.requestsHash = fields[20].toBytes32();
+        }
+    }
+
+    // Header.Hash() in go-ethereum
+    function hash(EthHeader memory h) internal pure returns (bytes32) {
+        return keccak256(encode(h));
+    }
+
+    // difficulty is zero and there are no uncles after the merge, the nonce is zero too
+    function isPostMerge(EthHeader memory h) internal pure returns (bool) {
+        return h.difficulty == 0 && h.uncleHash == EMPTY_UNCLE_HASH && h.nonce == bytes8(0);
+    }
+}
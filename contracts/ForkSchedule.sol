@@ -0,0 +1,67 @@
+// SPDX-License-Identifier: UNLICENSED
+pragma solidity >0.8.0;
+
+import "./HeaderCodec.sol";
+import "./IstanbulExtra.sol";
+
+// hard forks of the source chain that change how its headers are encoded. a header must carry
+// exactly the optional fields active at its number, and its extra is read with the layout active
+// at its number, so a relayer cannot pass a header off in the format of another fork.
+library ForkSchedule {
+    // the block of a fork that is not scheduled, a nil block in test/testdata/config.go.
+    // no header is past it, not even one numbered type(uint).max
+    uint internal constant NEVER = type(uint).max;
+
+    struct Schedule {
+        uint baseFeeBlock; // BaseFee from here on, EIP-1559
+        uint blobGasBlock; // BlobGasUsed, ExcessBlobGas and ParentBeaconRoot from here on, EIP-4844 and EIP-4788
+        // layouts[i] applies from layoutBlocks[i] up to layoutBlocks[i + 1], the first from block 0
+        uint[] layoutBlocks;
+        IstanbulExtra.Layout[] layouts;
+    }
+
+    // what a light client has to know about the source chain, fixed when it is deployed
+    struct ChainConfig {
+        uint epochSize;
+        uint checkpointInterval; // every header at a multiple of it is stored, 1 keeps them all
+        Schedule forks;
+    }
+
+    function validate(ChainConfig memory c) internal pure {
+        require(c.epochSize > 0, 'bad epoch size');
+        require(c.checkpointInterval > 0, 'bad checkpoint interval');
+        validate(c.forks);
+    }
+
+    function validate(Schedule memory s) internal pure {
+        require(s.blobGasBlock >= s.baseFeeBlock, 'bad fork schedule');
+        require(s.layouts.length > 0 && s.layouts.length == s.layoutBlocks.length, 'bad fork schedule');
+        require(s.layoutBlocks[0] == 0, 'bad fork schedule');
+        for (uint i = 0; i < s.layouts.length; i++) {
+            require(i == 0 || s.layoutBlocks[i] > s.layoutBlocks[i - 1], 'bad fork schedule');
+            IstanbulExtra.validateLayout(s.layouts[i]);
+        }
+    }
+
+    function layoutAt(Schedule memory s, uint number) internal pure returns (IstanbulExtra.Layout memory) {
+        uint i = s.layouts.length - 1;
+        while (s.layoutBlocks[i] > number) i--;
+        return s.layouts[i];
+    }
+
+    function isForked(uint forkBlock, uint number) internal pure returns (bool) {
+        return forkBlock != NEVER && number >= forkBlock;
+    }
+
+    function checkHeader(Schedule memory s, HeaderCodec.Header memory h) internal pure {
+        bool blobGas = isForked(s.blobGasBlock, h.number);
+        require(h.hasBaseFee == isForked(s.baseFeeBlock, h.number), 'header does not match fork');
+        require(h.hasBlobGas == blobGas && h.hasParentBeaconRoot == blobGas, 'header does not match fork');
+    }
+
+    // checkHeader, then Header.Hash() with the extra layout of the header's fork
+    function hash(Schedule memory s, HeaderCodec.Header memory h) internal pure returns (bytes32) {
+        checkHeader(s, h);
+        return HeaderCodec.hash(h, layoutAt(s, h.number));
+    }
+}
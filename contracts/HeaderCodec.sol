@@ -0,0 +1,155 @@
+// SPDX-License-Identifier: UNLICENSED
+pragma solidity >0.8.0;
+
+import "./RLPReader.sol";
+import "./RLPEncode.sol";
+import "./IstanbulExtra.sol";
+
+// rlp codec for the atlas Header, field order as in test/testdata/block.go
+library HeaderCodec {
+    using RLPReader for bytes;
+    using RLPReader for RLPReader.RLPItem;
+
+    struct Header {
+        bytes32 parentHash;
+        address coinbase;
+        bytes32 root;
+        bytes32 txHash;
+        bytes32 receiptHash;
+        bytes bloom;
+        uint number;
+        uint gasLimit;
+        uint gasUsed;
+        uint time;
+        bytes extra;
+        bytes32 mixDigest;
+        bytes8 nonce;
+        // BaseFee and the fields after it are `rlp:"optional"`: they are encoded up to the last
+        // one present, the ones before it as zero when absent
+        bool hasBaseFee;
+        uint baseFee;
+        // BlobGasUsed and ExcessBlobGas, EIP-4844
+        bool hasBlobGas;
+        uint blobGasUsed;
+        uint excessBlobGas;
+        // EIP-4788
+        bool hasParentBeaconRoot;
+        bytes32 parentBeaconRoot;
+    }
+
+    uint internal constant BLOOM_LENGTH = 256;
+    uint internal constant MSG_COMMIT = 2; // istanbul.MsgCommit
+
+    function encode(Header memory h) internal pure returns (bytes memory) {
+        return encode(h, h.extra);
+    }
+
+    // encodes h with its extra replaced
+    function encode(Header memory h, bytes memory extra) internal pure returns (bytes memory) {
+        bytes[] memory list = new bytes[](fieldCount(h));
+        list[0] = RLPEncode.encodeBytes32(h.parentHash);
+        list[1] = RLPEncode.encodeAddress(h.coinbase);
+        list[2] = RLPEncode.encodeBytes32(h.root);
+        list[3] = RLPEncode.encodeBytes32(h.txHash);
+        list[4] = RLPEncode.encodeBytes32(h.receiptHash);
+        list[5] = RLPEncode.encodeBytes(h.bloom);
+        list[6] = RLPEncode.encodeUint(h.number);
+        list[7] = RLPEncode.encodeUint(h.gasLimit);
+        list[8] = RLPEncode.encodeUint(h.gasUsed);
+        list[9] = RLPEncode.encodeUint(h.time);
+        list[10] = RLPEncode.encodeBytes(extra);
+        list[11] = RLPEncode.encodeBytes32(h.mixDigest);
+        list[12] = RLPEncode.encodeBytes(abi.encodePacked(h.nonce));
+        if (list.length > 13) list[13] = RLPEncode.encodeUint(h.baseFee);
+        if (list.length > 14) {
+            list[14] = RLPEncode.encodeUint(h.blobGasUsed);
+            list[15] = RLPEncode.encodeUint(h.excessBlobGas);
+        }
+        if (list.length > 16) list[16] = RLPEncode.encodeBytes32(h.parentBeaconRoot);
+
+        return RLPEncode.encodeList(list);
+    }
+
+    function fieldCount(Header memory h) private pure returns (uint) {
+        if (h.hasParentBeaconRoot) return 17;
+        if (h.hasBlobGas) return 16;
+        if (h.hasBaseFee) return 14;
+        return 13;
+    }
+
+    function decode(bytes memory data) internal pure returns (Header memory) {
+        return decode(data.toRlpItem());
+    }
+
+    function decode(RLPReader.RLPItem memory item) internal pure returns (Header memory h) {
+        RLPReader.RLPItem[] memory fields = item.toList();
+        // the blob gas fields come in pairs, see Header.SanityCheck
+        require(fields.length >= 13 && fields.length <= 17 && fields.length != 15, 'bad header');
+
+        h.parentHash = fields[0].toBytes32();
+        h.coinbase = fields[1].toAddress();
+        h.root = fields[2].toBytes32();
+        h.txHash = fields[3].toBytes32();
+        h.receiptHash = fields[4].toBytes32();
+        h.bloom = fields[5].toBytes();
+        require(h.bloom.length == BLOOM_LENGTH, 'bad header bloom');
+        h.number = fields[6].toUint();
+        h.gasLimit = fields[7].toUint64();
+        h.gasUsed = fields[8].toUint64();
+        h.time = fields[9].toUint64();
+        h.extra = fields[10].toBytes();
+        h.mixDigest = fields[11].toBytes32();
+        require(fields[12].len == 9, 'bad header nonce');
+        h.nonce = fields[12].toBytes8();
+        if (fields.length > 13) {
+            h.hasBaseFee = true;
+            h.baseFee = fields[13].toUint();
+        }
+        if (fields.length > 14) {
+            h.hasBlobGas = true;
+            h.blobGasUsed = fields[14].toUint64();
+            h.excessBlobGas = fields[15].toUint64();
+        }
+        if (fields.length > 16) {
+            h.hasParentBeaconRoot = true;
+            h.parentBeaconRoot = fields[16].toBytes32();
+        }
+    }
+
+    // Header.Hash() in atlas: headers carrying istanbul extra are hashed without the aggregated seal,
+    // headers with any other extra as they are
+    function hash(Header memory h) internal pure returns (bytes32) {
+        return hash(h, IstanbulExtra.atlasLayout());
+    }
+
+    function hash(Header memory h, IstanbulExtra.Layout memory layout) internal pure returns (bytes32) {
+        if (!IstanbulExtra.isExtra(h.extra, layout)) return keccak256(encode(h));
+        return keccak256(encode(h, IstanbulExtra.filter(h.extra, true, layout)));
+    }
+
+    // sigHash in atlas: the hash the proposer signs into the seal, the seal itself is dropped as well.
+    // without an istanbul extra atlas filters the header to nil, which rlp encodes as an empty list
+    function hashHeaderForSeal(Header memory h) internal pure returns (bytes32) {
+        return hashHeaderForSeal(h, IstanbulExtra.atlasLayout());
+    }
+
+    function hashHeaderForSeal(Header memory h, IstanbulExtra.Layout memory layout) internal pure returns (bytes32) {
+        if (!IstanbulExtra.isExtra(h.extra, layout)) return keccak256(hex"c0");
+        return keccak256(encode(h, IstanbulExtra.filter(h.extra, false, layout)));
+    }
+
+    // the message the validators sign into their committed seals of h in the given round
+    function committedSealMessage(Header memory h, uint round) internal pure returns (bytes memory) {
+        return committedSealMessage(hash(h), round);
+    }
+
+    // PrepareCommittedSeal in atlas: hash || round.Bytes() || MsgCommit
+    function committedSealMessage(bytes32 hash, uint round) internal pure returns (bytes memory) {
+        uint len = 0;
+        for (uint r = round; r != 0; r >>= 8) len++;
+
+        bytes memory roundBytes = new bytes(len);
+        for (uint i = 0; i < len; i++) roundBytes[i] = bytes1(uint8(round >> (8 * (len - 1 - i))));
+        return abi.encodePacked(hash, roundBytes, uint8(MSG_COMMIT));
+    }
+}
@@ -0,0 +1,45 @@
+// SPDX-License-Identifier: UNLICENSED
+pragma solidity >0.8.0;
+
+// finalized header commitments indexed both by number and by hash.
+// a height holds at most one hash: committing a different hash at a finalized
+// height means the source chain forked or the validator set equivocated, and reverts.
+library HeaderStore {
+    struct Entry {
+        uint number;
+        bool committed;
+    }
+
+    struct Store {
+        mapping(uint => bytes32) hashByNumber;
+        mapping(bytes32 => Entry) entryByHash;
+    }
+
+    // returns false if the same header was committed before
+    function commit(Store storage s, uint number, bytes32 hash) internal returns (bool) {
+        require(hash != bytes32(0), 'empty header hash');
+
+        bytes32 existing = s.hashByNumber[number];
+        if (existing == hash) return false;
+        require(existing == bytes32(0), 'conflicting header at height');
+        require(!s.entryByHash[hash].committed, 'conflicting header number');
+
+        s.hashByNumber[number] = hash;
+        s.entryByHash[hash] = Entry(number, true);
+        return true;
+    }
+
+    // zero if nothing is committed at number
+    function headerHashByNumber(Store storage s, uint number) internal view returns (bytes32) {
+        return s.hashByNumber[number];
+    }
+
+    function headerNumberByHash(Store storage s, bytes32 hash) internal view returns (bool, uint) {
+        Entry storage e = s.entryByHash[hash];
+        return (e.committed, e.number);
+    }
+
+    function isCommitted(Store storage s, uint number, bytes32 hash) internal view returns (bool) {
+        return hash != bytes32(0) && s.hashByNumber[number] == hash;
+    }
+}
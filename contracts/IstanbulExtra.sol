@@ -0,0 +1,162 @@
+// SPDX-License-Identifier: UNLICENSED
+pragma solidity >0.8.0;
+
+import "./RLPReader.sol";
+import "./RLPEncode.sol";
+
+// decodes the istanbul part of Header.Extra, the on-chain equivalent of ExtractIstanbulExtra in atlas
+//
+// extra = vanity (32 bytes) || rlp([
+//     AddedValidators, AddedValidatorsPublicKeys, AddedValidatorsG1PublicKeys,
+//     RemovedValidators, Seal, AggregatedSeal, ParentAggregatedSeal
+// ])
+// aggregated seal = rlp([Bitmap, Signature, Round])
+//
+// forks may use another vanity size or drop the G1 keys (celo), see Layout.
+// the plain decode and filter use the atlas layout.
+library IstanbulExtra {
+    using RLPReader for bytes;
+    using RLPReader for RLPReader.RLPItem;
+
+    uint internal constant VANITY = 32; // IstanbulExtraVanity
+    uint internal constant MAX_VANITY = 256;
+
+    struct AggregatedSeal {
+        uint bitmap;
+        bytes signature;
+        uint round;
+    }
+
+    struct Extra {
+        address[] addedValidators;
+        bytes[] addedPubKeys; // serialized G2 keys
+        bytes[] addedG1PubKeys; // empty for layouts without G1 keys
+        uint removedValidators; // bit i set: validator i of the previous set is removed
+        bytes seal; // proposer's ECDSA signature
+        AggregatedSeal aggregatedSeal;
+        AggregatedSeal parentAggregatedSeal;
+    }
+
+    // extra layout of a source chain
+    struct Layout {
+        uint vanity;
+        // atlas adds AddedValidatorsG1PublicKeys after the G2 keys, celo does not
+        bool hasG1PubKeys;
+    }
+
+    function atlasLayout() internal pure returns (Layout memory) {
+        return Layout(VANITY, true);
+    }
+
+    function celoLayout() internal pure returns (Layout memory) {
+        return Layout(VANITY, false);
+    }
+
+    function validateLayout(Layout memory layout) internal pure {
+        require(layout.vanity > 0 && layout.vanity <= MAX_VANITY, 'bad extra layout');
+    }
+
+    function fieldCount(Layout memory layout) internal pure returns (uint) {
+        return layout.hasG1PubKeys ? 7 : 6;
+    }
+
+    function decode(bytes memory extra) internal pure returns (Extra memory) {
+        return decode(extra, atlasLayout());
+    }
+
+    function decode(bytes memory extra, Layout memory layout) internal pure returns (Extra memory ist) {
+        RLPReader.RLPItem[] memory fields = fieldsOf(extra, layout);
+
+        uint i = 0;
+        ist.addedValidators = decodeAddresses(fields[i++]);
+        ist.addedPubKeys = decodeBytesList(fields[i++]);
+        require(ist.addedValidators.length == ist.addedPubKeys.length, 'mismatch added validators');
+        if (layout.hasG1PubKeys) {
+            ist.addedG1PubKeys = decodeBytesList(fields[i++]);
+            require(ist.addedValidators.length == ist.addedG1PubKeys.length, 'mismatch added validators');
+        }
+
+        ist.removedValidators = fields[i++].toUint();
+        ist.seal = fields[i++].toBytes();
+        ist.aggregatedSeal = decodeAggregatedSeal(fields[i++]);
+        ist.parentAggregatedSeal = decodeAggregatedSeal(fields[i]);
+    }
+
+    // whether extra is a vanity and an istanbul extra that rlp decodes in atlas. ExtractIstanbulExtra
+    // fails on any other extra, and atlas then hashes the header as it is, see HeaderCodec.hash.
+    // the added keys are only checked to be byte strings, as decode does
+    function isExtra(bytes memory extra, Layout memory layout) internal pure returns (bool) {
+        if (extra.length <= layout.vanity) return false;
+        (bool ok, RLPReader.RLPItem memory item) = RLPReader.tryToRlpItem(extra, layout.vanity);
+        RLPReader.RLPItem[] memory fields;
+        if (ok) (ok, fields) = item.tryToList();
+        if (!ok || fields.length != fieldCount(layout)) return false;
+
+        uint i = 0;
+        if (!isListOf(fields[i++], true)) return false;
+        if (!isListOf(fields[i++], false)) return false;
+        if (layout.hasG1PubKeys && !isListOf(fields[i++], false)) return false;
+        if (!fields[i++].isUint()) return false; // removed validators
+        if (fields[i++].isList()) return false; // seal
+        return isAggregatedSeal(fields[i++]) && isAggregatedSeal(fields[i]);
+    }
+
+    // a list of addresses, or of byte strings
+    function isListOf(RLPReader.RLPItem memory item, bool addresses) private pure returns (bool) {
+        (bool ok, RLPReader.RLPItem[] memory list) = item.tryToList();
+        for (uint i = 0; ok && i < list.length; i++) ok = addresses ? list[i].isAddress() : !list[i].isList();
+        return ok;
+    }
+
+    function isAggregatedSeal(RLPReader.RLPItem memory item) private pure returns (bool) {
+        (bool ok, RLPReader.RLPItem[] memory fields) = item.tryToList();
+        return ok && fields.length == 3 && fields[0].isUint() && !fields[1].isList() && fields[2].isUint();
+    }
+
+    function filter(bytes memory extra, bool keepSeal) internal pure returns (bytes memory) {
+        return filter(extra, keepSeal, atlasLayout());
+    }
+
+    // the extra of IstanbulFilteredHeader in atlas: the aggregated seal is always cleared,
+    // the proposer seal is kept only when keepSeal is set
+    function filter(bytes memory extra, bool keepSeal, Layout memory layout) internal pure returns (bytes memory) {
+        RLPReader.RLPItem[] memory fields = fieldsOf(extra, layout);
+
+        // seal and aggregated seal are the 3rd and 2nd last fields in every layout
+        uint n = fields.length;
+        bytes[] memory list = new bytes[](n);
+        for (uint i = 0; i < n; i++) list[i] = fields[i].toRlpBytes();
+        if (!keepSeal) list[n - 3] = hex"80";
+        list[n - 2] = hex"c3808080"; // IstanbulAggregatedSeal{}
+
+        bytes memory vanity = new bytes(layout.vanity);
+        for (uint i = 0; i < layout.vanity; i++) vanity[i] = extra[i];
+        return abi.encodePacked(vanity, RLPEncode.encodeList(list));
+    }
+
+    function fieldsOf(bytes memory extra, Layout memory layout) private pure returns (RLPReader.RLPItem[] memory fields) {
+        require(extra.length > layout.vanity, 'extra too short');
+
+        fields = extra.toRlpItem(layout.vanity).toList();
+        require(fields.length == fieldCount(layout), 'bad istanbul extra');
+    }
+
+    function decodeAggregatedSeal(RLPReader.RLPItem memory item) internal pure returns (AggregatedSeal memory) {
+        RLPReader.RLPItem[] memory fields = item.toList();
+        require(fields.length == 3, 'bad aggregated seal');
+
+        return AggregatedSeal(fields[0].toUint(), fields[1].toBytes(), fields[2].toUint());
+    }
+
+    function decodeAddresses(RLPReader.RLPItem memory item) private pure returns (address[] memory result) {
+        RLPReader.RLPItem[] memory list = item.toList();
+        result = new address[](list.length);
+        for (uint i = 0; i < list.length; i++) result[i] = list[i].toAddress();
+    }
+
+    function decodeBytesList(RLPReader.RLPItem memory item) private pure returns (bytes[] memory result) {
+        RLPReader.RLPItem[] memory list = item.toList();
+        result = new bytes[](list.length);
+        for (uint i = 0; i < list.length; i++) result[i] = list[i].toBytes();
+    }
+}
@@ -0,0 +1,45 @@
+// SPDX-License-Identifier: UNLICENSED
+pragma solidity >0.8.0;
+
+import "./BGLS.sol";
+
+// known answers for LightNode.selfTest, computed off-chain
+library KnownAnswers {
+    // 2 * (1, 2)
+    uint internal constant G1_DOUBLE_X = 0x030644e72e131a029b85045b68181585d97816a916871ca8d3c208c16d87cfd3;
+    uint internal constant G1_DOUBLE_Y = 0x15ed738c0e0a7c92e7845f96b2ae9c0a68a6a449e3538fc7ff3ebf7a5a18a2c4;
+
+    // BGLS.hashToG1WithDST("MAP_BLS_SIG_V1", "abc"), the hash seals are checked with
+    uint internal constant HASH_ABC_X = 0x0c55791fac89adcef510ce9b57b708602bfd8b8d5c75fecaee33b231df699883;
+    uint internal constant HASH_ABC_Y = 0x252aa86ce209a70db0815fcd0be77cd269bdf12689733701ecc01e65ccbaccef;
+
+    // header 15 of test/testdata/head.json and its block hash
+    bytes32 internal constant HEADER_HASH = 0xd33484921936ca2a7e5a33b21d081523fa9a36cd546e8634e8624ae40f0055dc;
+    bytes internal constant HEADER =
+        hex"f902cfa084d7a32ef42b42ce9eae66f5fbaf9d6471f8cbf62438be1ec6e5b2449ee2645b9416fdbcac4d4cc24dca47b9b80f58155a551ca2afa080132f365dda"
+        hex"2d7eab782e1d60fd67127387edfea5d49f65cc0432f7a7d09314a056e81f171bcc55a6ff8345e692c0f86e5b48e01b996cadc001622fb5e363b421a056e81f17"
+        hex"1bcc55a6ff8345e692c0f86e5b48e01b996cadc001622fb5e363b421b90100000000000000000000000000000000000000000000000000000000000000000000"
+        hex"00000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000"
+        hex"00000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000"
+        hex"00000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000"
+        hex"000000000000000000000000000000000000000000000000000000000000000f84012cbc75808462567031b8f5d9820304846765746888676f312e31352e3787"
+        hex"77696e646f7773000000000000f8d3c0c0c080b8414a6b4b3b457564fd5c6d713908ba31373b1849f0c9a596b1e7fd50d6b79410970e1b6f209bf2a80e5b27d8"
+        hex"6375878d8f48453a083e53344fa9435a00ee36935f01f84407b8401c15d5a95b0da21b7e7780e74ed65b60b0403c080a76648c6ec986b0d6cdba2e15a6764018"
+        hex"154b8f9feb7f537f28bb32554fa2b94b7beeaf364241d1d7c45ef680f8440fb84024849c71a246996f16e0cc7275f947c94e1e8793efec7316166696831138cd"
+        hex"a71be5d99d6118a94d39de6cc761e05c9829a708adca72e5ba344914a98522874d80a00000000000000000000000000000000000000000000000000000000000"
+        hex"00000088000000000000000085e8d4a51000";
+
+    function g1Double() internal pure returns (BGLS.G1 memory) {
+        return BGLS.G1(G1_DOUBLE_X, G1_DOUBLE_Y);
+    }
+
+    // 2 * the BGLS g2 generator
+    function g2Double() internal pure returns (BGLS.G2 memory) {
+        return BGLS.G2(
+            0x27dc7234fd11d3e8c36c59277c3e6f149d5cd3cfa9a62aee49f8130962b4b3b9,
+            0x203e205db4f19b37b60121b83a7333706db86431c6d835849957ed8c3928ad79,
+            0x04bb53b8977e5f92a0bc372742c4830944a59b4fe6b1c0466e2a6dad122b5d2e,
+            0x195e8aa5b7827463722b8c153931579d3505566b4edf48d498e185f0509de152
+        );
+    }
+}
@@ -0,0 +1,294 @@
+// SPDX-License-Identifier: UNLICENSED
+pragma solidity >0.8.0;
+
+import "./BGLS.sol";
+import "./BLSCodec.sol";
+import "./BN256G1.sol";
+import "./BN256G2.sol";
+import "./ForkSchedule.sol";
+import "./HeaderCodec.sol";
+import "./HeaderStore.sol";
+import "./IstanbulExtra.sol";
+import "./KnownAnswers.sol";
+import "./LightNodeCodec.sol";
+import "./LightNodeProof.sol";
+
+// light client of the MAP chain.
+// it starts from a trusted header that is either the genesis or the last header of an epoch, and
+// the validator set that header elects, which signs the headers of the following epoch. every
+// submitted header must extend the stored head and carry an aggregated seal of at least 2/3 of
+// the validators. the last header of an epoch (number % epochSize == 0) removes and adds
+// validators for the next epoch, the same way the istanbul validator set does in atlas.
+// headers are decoded and hashed by the fork schedule of the chain config it is deployed with.
+// the decoding and hashing is in LightNodeCodec, which it has to be linked with when deployed.
+// to bound its storage only checkpoints, the headers at multiples of the checkpoint interval or
+// of the epoch size, are stored along with the head. the headers in between are proven by
+// proveHeaderBetweenCheckpoints from a segment of the chain that links them to a stored header.
+// every header is logged with the relayer that proved it, which is also kept for checkpoints.
+// headers are submitted through the registry, which passes on the relayer it submits for.
+contract LightNode is BGLS {
+    using HeaderStore for HeaderStore.Store;
+
+    uint public constant MAX_VALIDATORS = 256; // the seal bitmap is a uint
+    // committed seals are signed over the domain message under this tag, hashed to G1 by
+    // try-and-increment. hashToG1 would let anyone move a seal to another header
+    bytes public constant SEAL_DST = "MAP_BLS_SIG_V1";
+
+    // what verifyHeader finds wrong with a header, Valid if submitHeader would accept it
+    enum HeaderStatus {Valid, UnexpectedNumber, ParentMismatch, BadSealBitmap, NotEnoughSigners, InvalidSeal}
+
+    address public immutable registry;
+    uint public immutable epochSize;
+    uint public immutable checkpointInterval;
+    uint public immutable baseFeeBlock;
+    uint public immutable blobGasBlock;
+    uint public firstNumber;
+    uint public headNumber;
+    bytes32 public headHash;
+
+    HeaderStore.Store headers;
+    G2[] validators;
+    // checkpoint hash => relayer that submitted it, HeaderProved has the relayer of every header
+    mapping(bytes32 => address) provers;
+    // extra layouts of the fork schedule, layouts[i] from layoutBlocks[i] on
+    uint[] layoutBlocks;
+    IstanbulExtra.Layout[] layouts;
+
+    event HeaderSubmitted(uint indexed number, bytes32 hash);
+    // provenance of an accepted header: the submitted rlp or compact header hashes to submission,
+    // gasUsed is the execution gas of the submission without the intrinsic and calldata cost
+    event HeaderProved(bytes32 indexed hash, address indexed relayer, bytes32 submission, uint gasUsed);
+    event ValidatorSetUpdated(uint indexed number, uint size);
+
+    modifier onlyRegistry() {
+        require(msg.sender == registry, 'only registry');
+        _;
+    }
+
+    constructor(ForkSchedule.ChainConfig memory config, uint number, bytes32 hash, G2[] memory _validators, address _registry) {
+        ForkSchedule.validate(config);
+        // a header inside an epoch does not tell which validators sign its successors
+        require(number % config.epochSize == 0, 'trusted header not at epoch boundary');
+        for (uint i = 0; i < _validators.length; i++) {
+            require(BN256G2.isInSubgroupG2(_validators[i]), 'invalid validator key');
+        }
+
+        registry = _registry;
+        epochSize = config.epochSize;
+        checkpointInterval = config.checkpointInterval;
+        baseFeeBlock = config.forks.baseFeeBlock;
+        blobGasBlock = config.forks.blobGasBlock;
+        for (uint i = 0; i < config.forks.layouts.length; i++) {
+            layoutBlocks.push(config.forks.layoutBlocks[i]);
+            layouts.push(config.forks.layouts[i]);
+        }
+        firstNumber = number;
+        headNumber = number;
+        headHash = hash;
+        headers.commit(number, hash);
+        setValidators(number, _validators);
+
+        // other chains read headers at the slot LightNodeProof has fixed
+        uint base;
+        assembly {
+            base := headers.slot
+        }
+        assert(base == LightNodeProof.HEADERS_SLOT);
+    }
+
+    // relayer is the one the registry submits for, it is logged as the prover of the header
+    function submitHeader(bytes memory rlpHeader, address relayer) public onlyRegistry {
+        uint gasStart = gasleft();
+        submit(LightNodeCodec.decode(rlpHeader), keccak256(rlpHeader), relayer, gasStart);
+    }
+
+    // submitHeader with the header in the cheaper encoding of CompactHeader
+    function submitCompactHeader(bytes memory compactHeader, address relayer) public onlyRegistry {
+        uint gasStart = gasleft();
+        submit(LightNodeCodec.decodeCompact(compactHeader), keccak256(compactHeader), relayer, gasStart);
+    }
+
+    // checks rlpHeader as submitHeader does without importing it, reverting only if it does not
+    // decode. a seal signature that is not a point on G1 is an InvalidSeal like a wrong one. lets
+    // the registry tell a forged seal from a header that lost a race. nothing is
+    // changed, it is not a view only because the precompile calls of the pairing check are not
+    function verifyHeader(bytes memory rlpHeader) public returns (HeaderStatus status) {
+        (status, , ) = check(LightNodeCodec.decode(rlpHeader));
+    }
+
+    function verifyCompactHeader(bytes memory compactHeader) public returns (HeaderStatus status) {
+        (status, , ) = check(LightNodeCodec.decodeCompact(compactHeader));
+    }
+
+    function submit(HeaderCodec.Header memory h, bytes32 submission, address relayer, uint gasStart) private {
+        (HeaderStatus status, bytes32 hash, IstanbulExtra.Extra memory ist) = check(h);
+        require(status != HeaderStatus.UnexpectedNumber, 'unexpected header number');
+        require(status != HeaderStatus.ParentMismatch, 'parent hash mismatch');
+        require(status != HeaderStatus.BadSealBitmap, 'bad seal bitmap');
+        require(status != HeaderStatus.NotEnoughSigners, 'not enough signers');
+        require(status == HeaderStatus.Valid, 'invalid aggregated seal');
+
+        headNumber = h.number;
+        headHash = hash;
+        emit HeaderSubmitted(h.number, hash);
+
+        if (h.number % epochSize == 0) updateValidators(h.number, ist);
+
+        if (isCheckpoint(h.number)) {
+            headers.commit(h.number, hash);
+            provers[hash] = relayer;
+        }
+        emit HeaderProved(hash, relayer, submission, gasStart - gasleft());
+    }
+
+    // the hash and extra are only set for headers that extend the head
+    function check(HeaderCodec.Header memory h) private returns (HeaderStatus, bytes32 hash, IstanbulExtra.Extra memory ist) {
+        if (h.number != headNumber + 1) return (HeaderStatus.UnexpectedNumber, hash, ist);
+        if (h.parentHash != headHash) return (HeaderStatus.ParentMismatch, hash, ist);
+
+        ForkSchedule.Schedule memory forks = schedule();
+        hash = LightNodeCodec.hash(forks, h);
+        ist = LightNodeCodec.extra(forks, h);
+        return (verifySeal(hash, ist.aggregatedSeal), hash, ist);
+    }
+
+    // runs known answers through the precompiles and decoders submitHeader relies on, reverting
+    // with the first mismatch. meant for an eth_call right after deploying on a new chain
+    function selfTest() public {
+        require(pairingCheck(KnownAnswers.g1Double(), g2, g1, KnownAnswers.g2Double()), 'self test: pairing');
+        require(!pairingCheck(g1, g2, KnownAnswers.g1Double(), g2), 'self test: pairing');
+
+        G1 memory h = hashToG1WithDST(SEAL_DST, "abc");
+        require(h.x == KnownAnswers.HASH_ABC_X && h.y == KnownAnswers.HASH_ABC_Y, 'self test: hash to G1');
+
+        LightNodeCodec.selfTest();
+    }
+
+    // proves the first header of segment, a run of consecutive rlp headers that ends in a stored
+    // header or the head, by checking that each header is the parent of the next. no seals are
+    // checked, the hash chain back from a verified header is enough
+    function proveHeaderBetweenCheckpoints(bytes[] memory segment) public view returns (uint number, bytes32 hash) {
+        require(segment.length > 0, 'empty header segment');
+
+        ForkSchedule.Schedule memory forks = schedule();
+        uint prevNumber;
+        bytes32 prevHash;
+        for (uint i = 0; i < segment.length; i++) {
+            HeaderCodec.Header memory h = LightNodeCodec.decode(segment[i]);
+            require(i == 0 || (h.number == prevNumber + 1 && h.parentHash == prevHash), 'broken header segment');
+            prevNumber = h.number;
+            prevHash = LightNodeCodec.hash(forks, h);
+            if (i == 0) (number, hash) = (prevNumber, prevHash);
+        }
+        require(isHeaderVerified(prevNumber, prevHash), 'header segment not anchored');
+    }
+
+    function isCheckpoint(uint number) public view returns (bool) {
+        return number % checkpointInterval == 0 || number % epochSize == 0;
+    }
+
+    function chainConfig() public view returns (ForkSchedule.ChainConfig memory) {
+        return ForkSchedule.ChainConfig(epochSize, checkpointInterval, schedule());
+    }
+
+    function schedule() internal view returns (ForkSchedule.Schedule memory) {
+        return ForkSchedule.Schedule(baseFeeBlock, blobGasBlock, layoutBlocks, layouts);
+    }
+
+    // the headers in [start, end] are verified. the checkpoints among them and the head are
+    // stored, the rest have to be proven with proveHeaderBetweenCheckpoints
+    function verifiableHeaderRange() public view returns (uint start, uint end) {
+        return (firstNumber, headNumber);
+    }
+
+    // zero for pruned headers
+    function headerHashByNumber(uint number) public view returns (bytes32) {
+        if (number == headNumber) return headHash;
+        return headers.headerHashByNumber(number);
+    }
+
+    // false for pruned headers
+    function isHeaderVerified(uint number, bytes32 hash) public view returns (bool) {
+        if (number == headNumber) return hash == headHash;
+        return headers.isCommitted(number, hash);
+    }
+
+    // relayer that submitted the checkpoint, zero for the trusted header and any other hash.
+    // the relayers of the headers in between are in their HeaderProved events
+    function whoProved(bytes32 hash) public view returns (address) {
+        return provers[hash];
+    }
+
+    // storage slot holding the hash of header number, for eth_getProof on this contract.
+    // LightNodeProof checks such proofs on other chains. only checkpoints have one
+    function headerHashSlot(uint number) public pure returns (bytes32) {
+        return LightNodeProof.headerHashSlot(number);
+    }
+
+    function getValidators() public view returns (G2[] memory) {
+        return validators;
+    }
+
+    // e(sig, g2) == e(H(SEAL_DST, committed seal message), sum of the keys picked by bitmap)
+    function verifySeal(bytes32 hash, IstanbulExtra.AggregatedSeal memory seal) internal returns (HeaderStatus) {
+        uint n = validators.length;
+        if (seal.bitmap >> n != 0) return HeaderStatus.BadSealBitmap;
+
+        uint[6] memory acc;
+        uint signers = 0;
+        for (uint i = 0; i < n; i++) {
+            if ((seal.bitmap >> i) & 1 == 0) continue;
+            acc = BN256G2.addJacobian(acc, BN256G2.fromAffine(validators[i]));
+            signers++;
+        }
+        // MinQuorumSize = ceil(2n / 3)
+        if (3 * signers < 2 * n) return HeaderStatus.NotEnoughSigners;
+
+        (bool ok, G1 memory sig) = decodeSignature(seal.signature);
+        if (!ok) return HeaderStatus.InvalidSeal;
+        bytes memory message = HeaderCodec.committedSealMessage(hash, seal.round);
+        if (!checkSignatureWithDST(SEAL_DST, message, sig, BN256G2.toAffine(acc))) return HeaderStatus.InvalidSeal;
+        return HeaderStatus.Valid;
+    }
+
+    // removed validators are dropped keeping the order of the rest, added ones are appended
+    function updateValidators(uint number, IstanbulExtra.Extra memory ist) private {
+        uint n = validators.length;
+        require(ist.removedValidators >> n == 0, 'bad removed validators');
+
+        uint kept = 0;
+        for (uint i = 0; i < n; i++) {
+            if ((ist.removedValidators >> i) & 1 == 0) kept++;
+        }
+
+        G2[] memory next = new G2[](kept + ist.addedPubKeys.length);
+        uint j = 0;
+        for (uint i = 0; i < n; i++) {
+            if ((ist.removedValidators >> i) & 1 == 0) next[j++] = validators[i];
+        }
+        for (uint i = 0; i < ist.addedPubKeys.length; i++) next[j++] = BLSCodec.decodeG2(ist.addedPubKeys[i]);
+
+        setValidators(number, next);
+    }
+
+    function setValidators(uint number, G2[] memory set) private {
+        require(set.length > 0, 'empty validator set');
+        require(set.length <= MAX_VALIDATORS, 'too many validators');
+
+        delete validators;
+        for (uint i = 0; i < set.length; i++) validators.push(set[i]);
+        emit ValidatorSetUpdated(number, set.length);
+    }
+
+    // x || y, coordinates below the field modulus so a signature has one encoding. not ok for
+    // anything that is not a point on G1, which the pairing precompile would revert on
+    function decodeSignature(bytes memory data) private pure returns (bool ok, G1 memory sig) {
+        if (data.length != BLSCodec.G1_LENGTH) return (false, sig);
+
+        assembly {
+            mstore(sig, mload(add(data, 0x20)))
+            mstore(add(sig, 0x20), mload(add(data, 0x40)))
+        }
+        return (BN256G1.isOnCurveG1(sig), sig);
+    }
+}
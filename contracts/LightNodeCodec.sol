@@ -0,0 +1,36 @@
+// SPDX-License-Identifier: UNLICENSED
+pragma solidity >0.8.0;
+
+import "./CompactHeader.sol";
+import "./ForkSchedule.sol";
+import "./HeaderCodec.sol";
+import "./IstanbulExtra.sol";
+import "./KnownAnswers.sol";
+
+// the header decoding and hashing of LightNode. its functions are public, so it is deployed once
+// and linked into LightNode instead of inlined, which keeps LightNode clear of the EIP-170 limit
+library LightNodeCodec {
+    function decode(bytes memory rlpHeader) public pure returns (HeaderCodec.Header memory) {
+        return HeaderCodec.decode(rlpHeader);
+    }
+
+    function decodeCompact(bytes memory compactHeader) public pure returns (HeaderCodec.Header memory) {
+        return CompactHeader.decode(compactHeader);
+    }
+
+    // ForkSchedule.hash, reverting for a header that does not match its fork
+    function hash(ForkSchedule.Schedule memory forks, HeaderCodec.Header memory h) public pure returns (bytes32) {
+        return ForkSchedule.hash(forks, h);
+    }
+
+    // the istanbul extra of h, read with the layout of its fork
+    function extra(ForkSchedule.Schedule memory forks, HeaderCodec.Header memory h) public pure returns (IstanbulExtra.Extra memory) {
+        return IstanbulExtra.decode(h.extra, ForkSchedule.layoutAt(forks, h.number));
+    }
+
+    // the known answers of LightNode.selfTest for the code linked from here
+    function selfTest() public pure {
+        bytes32 h = HeaderCodec.hash(HeaderCodec.decode(KnownAnswers.HEADER));
+        require(h == KnownAnswers.HEADER_HASH, 'self test: header rlp');
+    }
+}
@@ -0,0 +1,25 @@
+// SPDX-License-Identifier: UNLICENSED
+pragma solidity >0.8.0;
+
+import "./MPTVerify.sol";
+
+// reads the header hashes a LightNode deployment has stored, on another chain.
+// given a trusted state root of the chain the LightNode runs on, a storage proof of its
+// hashByNumber mapping (eth_getProof on LightNode.headerHashSlot) shows which MAP header
+// that deployment accepted at a number, without a connection to the MAP chain.
+library LightNodeProof {
+    // storage slot of LightNode.headers, the hashByNumber mapping is its first member.
+    // LightNode asserts it when deployed
+    uint internal constant HEADERS_SLOT = 13;
+
+    function headerHashSlot(uint number) internal pure returns (bytes32) {
+        return keccak256(abi.encode(number, HEADERS_SLOT));
+    }
+
+    // zero when the LightNode had no header at number
+    function verifyHeaderHash(
+        bytes32 stateRoot, address lightNode, uint number, bytes[] memory accountProof, bytes[] memory storageProof
+    ) internal pure returns (bytes32) {
+        return bytes32(MPTVerify.getStorage(stateRoot, lightNode, headerHashSlot(number), accountProof, storageProof));
+    }
+}
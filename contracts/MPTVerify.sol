@@ -0,0 +1,145 @@
+// SPDX-License-Identifier: UNLICENSED
+pragma solidity >0.8.0;
+
+import "./RLPReader.sol";
+import "./RLPEncode.sol";
+
+// merkle patricia trie proofs, https://eth.wiki/fundamentals/patricia-tree
+// a proof is the list of rlp encoded nodes on the path from the root to the key, as built by
+// trie.Prove in go-ethereum, nodes shorter than 32 bytes are embedded in their parent and not listed.
+library MPTVerify {
+    using RLPReader for bytes;
+    using RLPReader for RLPReader.RLPItem;
+
+    // looks key up in the trie with the given root, found is false for a valid proof of absence
+    function get(bytes32 root, bytes memory key, bytes[] memory proof) internal pure returns (bool found, bytes memory value) {
+        bytes memory nibbles = toNibbles(key);
+        uint keyIndex = 0;
+        RLPReader.RLPItem memory node = loadNode(proof, 0, root);
+
+        for (uint p = 1; ; ) {
+            RLPReader.RLPItem[] memory items = node.toList();
+            RLPReader.RLPItem memory ref;
+
+            if (items.length == 17) {
+                if (keyIndex == nibbles.length) return valueOf(items[16]);
+                ref = items[uint8(nibbles[keyIndex++])];
+            } else {
+                require(items.length == 2, 'bad trie node');
+
+                (bool isLeaf, bool matched, uint len) = matchPath(items[0].toBytes(), nibbles, keyIndex);
+                if (!matched) return (false, '');
+                keyIndex += len;
+                if (isLeaf) {
+                    if (keyIndex != nibbles.length) return (false, '');
+                    return valueOf(items[1]);
+                }
+                ref = items[1];
+            }
+
+            // short nodes are embedded in the parent, everything else is referenced by hash
+            if (ref.isList()) {
+                node = ref;
+                continue;
+            }
+            if (ref.toBytes().length == 0) return (false, ''); // empty slot
+            node = loadNode(proof, p++, ref.toBytes32());
+        }
+    }
+
+    // consensus encoded receipt at index in the block with the given ReceiptHash
+    function verifyReceipt(bytes32 receiptsRoot, uint index, bytes memory receipt, bytes[] memory proof) internal pure returns (bool) {
+        (bool found, bytes memory value) = get(receiptsRoot, RLPEncode.encodeUint(index), proof);
+        return found && keccak256(value) == keccak256(receipt);
+    }
+
+    // consensus encoded transaction at index in the block with the given TxHash, typed
+    // transactions are type || rlp as in the trie
+    function verifyTransaction(bytes32 txRoot, uint index, bytes memory transaction, bytes[] memory proof) internal pure returns (bool) {
+        (bool found, bytes memory value) = get(txRoot, RLPEncode.encodeUint(index), proof);
+        return found && keccak256(value) == keccak256(transaction);
+    }
+
+    // receipt proofs of one block share the nodes near the root. a bundle lists every node once
+    // and each proof as indices into that list, which keeps repeated nodes out of the calldata
+    struct ProofBundle {
+        bytes[] nodes;
+        uint[][] proofs;
+    }
+
+    function unpack(ProofBundle memory bundle, uint i) internal pure returns (bytes[] memory proof) {
+        uint[] memory refs = bundle.proofs[i];
+        proof = new bytes[](refs.length);
+        for (uint j = 0; j < refs.length; j++) {
+            require(refs[j] < bundle.nodes.length, 'bad proof node index');
+            proof[j] = bundle.nodes[refs[j]];
+        }
+    }
+
+    // verifyReceipt for receipts[i] at indices[i] with the i-th proof of the bundle
+    function verifyReceipts(
+        bytes32 receiptsRoot, uint[] memory indices, bytes[] memory receipts, ProofBundle memory bundle
+    ) internal pure returns (bool) {
+        require(indices.length == receipts.length && receipts.length == bundle.proofs.length, 'mismatch receipt batch');
+        for (uint i = 0; i < receipts.length; i++) {
+            if (!verifyReceipt(receiptsRoot, indices[i], receipts[i], unpack(bundle, i))) return false;
+        }
+        return true;
+    }
+
+    // value of slot in the storage of account, zero when the account or the slot is absent
+    function getStorage(
+        bytes32 stateRoot, address account, bytes32 slot, bytes[] memory accountProof, bytes[] memory storageProof
+    ) internal pure returns (uint) {
+        (bool found, bytes memory value) = get(stateRoot, abi.encodePacked(keccak256(abi.encodePacked(account))), accountProof);
+        if (!found) return 0;
+
+        // [nonce, balance, storageRoot, codeHash]
+        RLPReader.RLPItem[] memory fields = value.toRlpItem().toList();
+        require(fields.length == 4, 'bad account');
+
+        (found, value) = get(fields[2].toBytes32(), abi.encodePacked(keccak256(abi.encodePacked(slot))), storageProof);
+        if (!found) return 0;
+        return value.toRlpItem().toUint();
+    }
+
+    function loadNode(bytes[] memory proof, uint index, bytes32 hash) private pure returns (RLPReader.RLPItem memory) {
+        require(index < proof.length, 'trie proof too short');
+        require(keccak256(proof[index]) == hash, 'bad trie proof');
+        return proof[index].toRlpItem();
+    }
+
+    function valueOf(RLPReader.RLPItem memory item) private pure returns (bool, bytes memory) {
+        bytes memory value = item.toBytes();
+        return (value.length != 0, value);
+    }
+
+    // decodes the hex prefix encoded path and checks it against nibbles[offset:]
+    function matchPath(bytes memory path, bytes memory nibbles, uint offset) private pure returns (bool isLeaf, bool matched, uint len) {
+        require(path.length > 0, 'bad trie path');
+
+        uint8 flag = uint8(path[0]) >> 4;
+        require(flag < 4, 'bad trie path');
+        isLeaf = flag >= 2;
+
+        // odd length paths carry their first nibble next to the flag
+        uint skip = flag % 2 == 1 ? 1 : 2;
+        len = path.length * 2 - skip;
+        if (offset + len > nibbles.length) return (isLeaf, false, len);
+
+        for (uint i = 0; i < len; i++) {
+            uint j = i + skip;
+            uint8 n = j % 2 == 0 ? uint8(path[j / 2]) >> 4 : uint8(path[j / 2]) & 0x0f;
+            if (n != uint8(nibbles[offset + i])) return (isLeaf, false, len);
+        }
+        matched = true;
+    }
+
+    function toNibbles(bytes memory b) private pure returns (bytes memory nibbles) {
+        nibbles = new bytes(b.length * 2);
+        for (uint i = 0; i < b.length; i++) {
+            nibbles[2 * i] = bytes1(uint8(b[i]) >> 4);
+            nibbles[2 * i + 1] = bytes1(uint8(b[i]) & 0x0f);
+        }
+    }
+}
@@ -0,0 +1,83 @@
+// SPDX-License-Identifier: UNLICENSED
+pragma solidity >0.8.0;
+
+// RLP encoding, https://eth.wiki/fundamentals/rlp
+// the output matches rlp.EncodeToBytes in go-ethereum for the same values
+library RLPEncode {
+    function encodeBytes(bytes memory self) internal pure returns (bytes memory) {
+        if (self.length == 1 && uint8(self[0]) < 0x80) return self;
+        return abi.encodePacked(encodeLength(self.length, 0x80), self);
+    }
+
+    // items must already be encoded
+    function encodeList(bytes[] memory self) internal pure returns (bytes memory) {
+        bytes memory payload = concat(self);
+        return abi.encodePacked(encodeLength(payload.length, 0xc0), payload);
+    }
+
+    function encodeUint(uint self) internal pure returns (bytes memory) {
+        return encodeBytes(toBinary(self));
+    }
+
+    function encodeAddress(address self) internal pure returns (bytes memory) {
+        return encodeBytes(abi.encodePacked(self));
+    }
+
+    function encodeBytes32(bytes32 self) internal pure returns (bytes memory) {
+        return encodeBytes(abi.encodePacked(self));
+    }
+
+    function encodeLength(uint len, uint offset) private pure returns (bytes memory) {
+        if (len < 56) return abi.encodePacked(uint8(len + offset));
+
+        bytes memory lenBytes = toBinary(len);
+        return abi.encodePacked(uint8(lenBytes.length + offset + 55), lenBytes);
+    }
+
+    // minimal big endian bytes, empty for 0
+    function toBinary(uint x) private pure returns (bytes memory b) {
+        uint n = 0;
+        for (uint v = x; v != 0; v >>= 8) n++;
+
+        b = new bytes(n);
+        for (uint i = 0; i < n; i++) b[n - 1 - i] = bytes1(uint8(x >> (8 * i)));
+    }
+
+    function concat(bytes[] memory list) private pure returns (bytes memory result) {
+        uint len = 0;
+        for (uint i = 0; i < list.length; i++) len += list[i].length;
+
+        result = new bytes(len);
+        uint destPtr;
+        assembly {
+            destPtr := add(result, 0x20)
+        }
+        for (uint i = 0; i < list.length; i++) {
+            bytes memory item = list[i];
+            uint srcPtr;
+            assembly {
+                srcPtr := add(item, 0x20)
+            }
+            copy(srcPtr, destPtr, item.length);
+            destPtr += item.length;
+        }
+    }
+
+    function copy(uint src, uint dest, uint len) private pure {
+        for (; len >= 32; len -= 32) {
+            assembly {
+                mstore(dest, mload(src))
+            }
+            src += 32;
+            dest += 32;
+        }
+        if (len == 0) return;
+
+        uint mask = 256 ** (32 - len) - 1;
+        assembly {
+            let srcpart := and(mload(src), not(mask))
+            let destpart := and(mload(dest), mask)
+            mstore(dest, or(destpart, srcpart))
+        }
+    }
+}
@@ -0,0 +1,264 @@
+// SPDX-License-Identifier: UNLICENSED
+pragma solidity >0.8.0;
+
+// RLP decoding over memory, https://eth.wiki/fundamentals/rlp
+// items are views into the input bytes, nothing is copied until a value is read out.
+// every length is checked against the enclosing item, malformed or non-canonical input reverts.
+library RLPReader {
+    uint8 constant STRING_SHORT_START = 0x80;
+    uint8 constant STRING_LONG_START = 0xb8;
+    uint8 constant LIST_SHORT_START = 0xc0;
+    uint8 constant LIST_LONG_START = 0xf8;
+
+    struct RLPItem {
+        uint len;
+        uint memPtr;
+    }
+
+    // the encoded item must span the whole input
+    function toRlpItem(bytes memory item) internal pure returns (RLPItem memory) {
+        return toRlpItem(item, 0);
+    }
+
+    // the encoded item must span item[offset:]
+    function toRlpItem(bytes memory item, uint offset) internal pure returns (RLPItem memory) {
+        require(offset < item.length, 'empty rlp item');
+
+        uint memPtr;
+        assembly {
+            memPtr := add(add(item, 0x20), offset)
+        }
+        uint len = item.length - offset;
+        require(itemLength(memPtr, len) == len, 'invalid rlp length');
+
+        return RLPItem(len, memPtr);
+    }
+
+    // toRlpItem without reverting, ok is false where toRlpItem reverts
+    function tryToRlpItem(bytes memory item, uint offset) internal pure returns (bool ok, RLPItem memory) {
+        if (offset >= item.length) return (false, RLPItem(0, 0));
+
+        uint memPtr;
+        assembly {
+            memPtr := add(add(item, 0x20), offset)
+        }
+        uint len = item.length - offset;
+        return (tryItemLength(memPtr, len) == len, RLPItem(len, memPtr));
+    }
+
+    function isList(RLPItem memory item) internal pure returns (bool) {
+        return byteAt(item.memPtr) >= LIST_SHORT_START;
+    }
+
+    function numItems(RLPItem memory item) internal pure returns (uint count) {
+        require(isList(item), 'rlp item is not a list');
+
+        uint memPtr = item.memPtr + payloadOffset(item.memPtr);
+        uint end = item.memPtr + item.len;
+        while (memPtr < end) {
+            memPtr += itemLength(memPtr, end - memPtr);
+            count++;
+        }
+    }
+
+    function toList(RLPItem memory item) internal pure returns (RLPItem[] memory result) {
+        result = new RLPItem[](numItems(item));
+
+        uint memPtr = item.memPtr + payloadOffset(item.memPtr);
+        uint end = item.memPtr + item.len;
+        for (uint i = 0; i < result.length; i++) {
+            uint len = itemLength(memPtr, end - memPtr);
+            result[i] = RLPItem(len, memPtr);
+            memPtr += len;
+        }
+    }
+
+    // toList without reverting, ok is false where toList reverts. the items are only measured,
+    // what they hold is left to the checks below
+    function tryToList(RLPItem memory item) internal pure returns (bool ok, RLPItem[] memory result) {
+        if (!isList(item)) return (false, result);
+
+        uint start = item.memPtr + payloadOffset(item.memPtr);
+        uint end = item.memPtr + item.len;
+        uint count = 0;
+        for (uint memPtr = start; memPtr < end; count++) {
+            uint len = tryItemLength(memPtr, end - memPtr);
+            if (len == 0) return (false, result);
+            memPtr += len;
+        }
+
+        result = new RLPItem[](count);
+        for (uint i = 0; i < count; i++) {
+            uint len = tryItemLength(start, end - start);
+            result[i] = RLPItem(len, start);
+            start += len;
+        }
+        return (true, result);
+    }
+
+    // whether toUint takes item
+    function isUint(RLPItem memory item) internal pure returns (bool) {
+        if (isList(item)) return false;
+
+        uint offset = payloadOffset(item.memPtr);
+        uint len = item.len - offset;
+        return len == 0 || (len <= 32 && byteAt(item.memPtr + offset) != 0);
+    }
+
+    // whether toAddress takes item
+    function isAddress(RLPItem memory item) internal pure returns (bool) {
+        return !isList(item) && item.len == 21;
+    }
+
+    // the whole encoding of the item, prefix included
+    function toRlpBytes(RLPItem memory item) internal pure returns (bytes memory result) {
+        result = new bytes(item.len);
+        uint destPtr;
+        assembly {
+            destPtr := add(result, 0x20)
+        }
+        copy(item.memPtr, destPtr, item.len);
+    }
+
+    function rlpBytesKeccak256(RLPItem memory item) internal pure returns (bytes32 result) {
+        uint memPtr = item.memPtr;
+        uint len = item.len;
+        assembly {
+            result := keccak256(memPtr, len)
+        }
+    }
+
+    // the payload of a string item
+    function toBytes(RLPItem memory item) internal pure returns (bytes memory result) {
+        require(!isList(item), 'rlp item is a list');
+
+        uint offset = payloadOffset(item.memPtr);
+        uint len = item.len - offset;
+        result = new bytes(len);
+        uint destPtr;
+        assembly {
+            destPtr := add(result, 0x20)
+        }
+        copy(item.memPtr + offset, destPtr, len);
+    }
+
+    // big endian integer of at most 32 bytes, the empty string is 0.
+    // like the go rlp decoder, an integer with leading zero bytes is non-canonical.
+    function toUint(RLPItem memory item) internal pure returns (uint) {
+        require(!isList(item), 'rlp item is a list');
+
+        uint offset = payloadOffset(item.memPtr);
+        uint len = item.len - offset;
+        require(len <= 32, 'rlp uint too large');
+        if (len == 0) return 0;
+        require(byteAt(item.memPtr + offset) != 0, 'non-canonical rlp');
+
+        return readUint(item.memPtr + offset, len);
+    }
+
+    // the go rlp decoder only takes 8 bytes into a uint64 field
+    function toUint64(RLPItem memory item) internal pure returns (uint result) {
+        result = toUint(item);
+        require(result <= type(uint64).max, 'rlp uint64 too large');
+    }
+
+    function toAddress(RLPItem memory item) internal pure returns (address) {
+        require(!isList(item) && item.len == 21, 'invalid rlp address');
+        return address(uint160(readUint(item.memPtr + 1, 20)));
+    }
+
+    function toBytes32(RLPItem memory item) internal pure returns (bytes32) {
+        require(!isList(item) && item.len == 33, 'invalid rlp bytes32');
+        return bytes32(readUint(item.memPtr + 1, 32));
+    }
+
+    function toBytes8(RLPItem memory item) internal pure returns (bytes8) {
+        require(!isList(item) && item.len == 9, 'invalid rlp bytes8');
+        return bytes8(uint64(readUint(item.memPtr + 1, 8)));
+    }
+
+    // fixed width values keep their leading zeros
+    function readUint(uint memPtr, uint len) private pure returns (uint result) {
+        assembly {
+            result := shr(mul(8, sub(32, len)), mload(memPtr))
+        }
+    }
+
+    function byteAt(uint memPtr) private pure returns (uint8 b) {
+        assembly {
+            b := byte(0, mload(memPtr))
+        }
+    }
+
+    function payloadOffset(uint memPtr) private pure returns (uint) {
+        uint8 prefix = byteAt(memPtr);
+        if (prefix < STRING_SHORT_START) return 0;
+        if (prefix < STRING_LONG_START) return 1;
+        if (prefix < LIST_SHORT_START) return prefix - STRING_LONG_START + 2;
+        if (prefix < LIST_LONG_START) return 1;
+        return prefix - LIST_LONG_START + 2;
+    }
+
+    // total length of the item at memPtr, which must fit in the maxLen bytes that are left
+    function itemLength(uint memPtr, uint maxLen) private pure returns (uint len) {
+        bool canonical;
+        (len, canonical) = measure(memPtr, maxLen);
+        require(canonical, 'non-canonical rlp');
+        require(len > 0 && len <= maxLen, 'rlp item out of bounds');
+    }
+
+    // itemLength without reverting, zero when the item is malformed or does not fit
+    function tryItemLength(uint memPtr, uint maxLen) private pure returns (uint) {
+        (uint len, bool canonical) = measure(memPtr, maxLen);
+        return canonical && len <= maxLen ? len : 0;
+    }
+
+    // total length of the item at memPtr and whether its prefix is canonical. len is zero when
+    // the prefix itself does not fit in maxLen
+    function measure(uint memPtr, uint maxLen) private pure returns (uint len, bool canonical) {
+        if (maxLen == 0) return (0, true);
+
+        uint8 prefix = byteAt(memPtr);
+        if (prefix < STRING_SHORT_START) return (1, true);
+        if (prefix < STRING_LONG_START) {
+            len = prefix - STRING_SHORT_START + 1;
+            // a single byte below 0x80 is its own encoding
+            return (len, len != 2 || maxLen < 2 || byteAt(memPtr + 1) >= STRING_SHORT_START);
+        }
+        if (prefix < LIST_SHORT_START) return measureLong(memPtr, prefix - STRING_LONG_START + 1, maxLen);
+        if (prefix < LIST_LONG_START) return (prefix - LIST_SHORT_START + 1, true);
+        return measureLong(memPtr, prefix - LIST_LONG_START + 1, maxLen);
+    }
+
+    // 1 + lenOfLen + payload length, for the long string and long list forms
+    function measureLong(uint memPtr, uint lenOfLen, uint maxLen) private pure returns (uint, bool) {
+        if (1 + lenOfLen > maxLen) return (0, true);
+
+        uint payloadLen;
+        assembly {
+            payloadLen := shr(mul(8, sub(32, lenOfLen)), mload(add(memPtr, 1)))
+        }
+        // no leading zeros in the length, and no long form for a short payload
+        bool canonical = byteAt(memPtr + 1) != 0 && payloadLen >= 56;
+        if (payloadLen > maxLen) return (0, canonical);
+        return (1 + lenOfLen + payloadLen, canonical);
+    }
+
+    function copy(uint src, uint dest, uint len) private pure {
+        for (; len >= 32; len -= 32) {
+            assembly {
+                mstore(dest, mload(src))
+            }
+            src += 32;
+            dest += 32;
+        }
+        if (len == 0) return;
+
+        uint mask = 256 ** (32 - len) - 1;
+        assembly {
+            let srcpart := and(mload(src), not(mask))
+            let destpart := and(mload(dest), mask)
+            mstore(dest, or(destpart, srcpart))
+        }
+    }
+}
@@ -0,0 +1,149 @@
+// SPDX-License-Identifier: UNLICENSED
+pragma solidity >0.8.0;
+
+// the part of LightNode a registry submits to
+interface IHeaderSubmitter {
+    // LightNode.HeaderStatus
+    enum HeaderStatus {Valid, UnexpectedNumber, ParentMismatch, BadSealBitmap, NotEnoughSigners, InvalidSeal}
+
+    function submitHeader(bytes memory rlpHeader, address relayer) external;
+    function submitCompactHeader(bytes memory compactHeader, address relayer) external;
+    function verifyHeader(bytes memory rlpHeader) external returns (HeaderStatus);
+    function verifyCompactHeader(bytes memory compactHeader) external returns (HeaderStatus);
+}
+
+// whitelisted, staked relayers submit headers to the light node through the registry, which is
+// the only submitter the light node takes. a header whose seal does not verify can only be
+// forged, so submitting one slashes the whole stake of the relayer and removes it, instead of
+// reverting. any other failure, such as losing a race for the next header, reverts as it would
+// on the light node. every submission is accepted once, so the same rlp or compact header cannot
+// be credited to a relayer twice.
+contract RelayerRegistry {
+    struct Relayer {
+        bool whitelisted;
+        uint stake;
+    }
+
+    IHeaderSubmitter public immutable node;
+    address public immutable owner;
+    uint public immutable minStake;
+    uint public slashed; // stake taken from slashed relayers, collected by the owner
+
+    mapping(address => Relayer) public relayers;
+    // keccak of the submitted rlp => relayer that submitted it
+    mapping(bytes32 => address) public submitters;
+
+    event RelayerAdded(address indexed relayer);
+    event RelayerRemoved(address indexed relayer);
+    event Staked(address indexed relayer, uint amount);
+    event Withdrawn(address indexed relayer, uint amount);
+    event Slashed(address indexed relayer, uint amount, IHeaderSubmitter.HeaderStatus status);
+    event HeaderRelayed(address indexed relayer, bytes32 submission);
+
+    modifier onlyOwner() {
+        require(msg.sender == owner, 'only owner');
+        _;
+    }
+
+    constructor(IHeaderSubmitter _node, uint _minStake) {
+        node = _node;
+        owner = msg.sender;
+        minStake = _minStake;
+    }
+
+    function addRelayer(address relayer) public onlyOwner {
+        relayers[relayer].whitelisted = true;
+        emit RelayerAdded(relayer);
+    }
+
+    function removeRelayer(address relayer) public onlyOwner {
+        relayers[relayer].whitelisted = false;
+        emit RelayerRemoved(relayer);
+    }
+
+    function stake() public payable {
+        relayers[msg.sender].stake += msg.value;
+        emit Staked(msg.sender, msg.value);
+    }
+
+    // the stake stays locked while the relayer may still submit
+    function withdraw(uint amount) public {
+        Relayer storage r = relayers[msg.sender];
+        require(!r.whitelisted, 'stake locked');
+        require(amount <= r.stake, 'insufficient stake');
+
+        r.stake -= amount;
+        (bool ok, ) = msg.sender.call{value: amount}('');
+        require(ok, 'transfer failed');
+        emit Withdrawn(msg.sender, amount);
+    }
+
+    function collectSlashed(address payable to) public onlyOwner {
+        uint amount = slashed;
+        slashed = 0;
+        (bool ok, ) = to.call{value: amount}('');
+        require(ok, 'transfer failed');
+    }
+
+    function isRelayer(address relayer) public view returns (bool) {
+        return relayers[relayer].whitelisted && relayers[relayer].stake >= minStake;
+    }
+
+    function submitHeader(bytes memory rlpHeader) public {
+        bytes32 submission = checkSubmission(rlpHeader);
+        try node.submitHeader(rlpHeader, msg.sender) {
+            relayed(submission);
+        } catch (bytes memory reason) {
+            rejected(node.verifyHeader(rlpHeader), reason);
+        }
+    }
+
+    function submitCompactHeader(bytes memory compactHeader) public {
+        bytes32 submission = checkSubmission(compactHeader);
+        try node.submitCompactHeader(compactHeader, msg.sender) {
+            relayed(submission);
+        } catch (bytes memory reason) {
+            rejected(node.verifyCompactHeader(compactHeader), reason);
+        }
+    }
+
+    function checkSubmission(bytes memory header) private view returns (bytes32 submission) {
+        require(isRelayer(msg.sender), 'not a relayer');
+        submission = keccak256(header);
+        require(submitters[submission] == address(0), 'header already relayed');
+    }
+
+    function relayed(bytes32 submission) private {
+        submitters[submission] = msg.sender;
+        emit HeaderRelayed(msg.sender, submission);
+    }
+
+    // slashes for a forged seal, otherwise reverts with the reason of the light node
+    function rejected(IHeaderSubmitter.HeaderStatus status, bytes memory reason) private {
+        if (!isForgery(status)) {
+            assembly {
+                revert(add(reason, 0x20), mload(reason))
+            }
+        }
+        slash(msg.sender, status);
+    }
+
+    // the statuses of a seal that does not verify
+    function isForgery(IHeaderSubmitter.HeaderStatus status) internal pure returns (bool) {
+        return status == IHeaderSubmitter.HeaderStatus.BadSealBitmap || status == IHeaderSubmitter.HeaderStatus.NotEnoughSigners || status == IHeaderSubmitter.HeaderStatus.InvalidSeal;
+    }
+
+    function slash(address relayer, IHeaderSubmitter.HeaderStatus status) internal {
+        Relayer storage r = relayers[relayer];
+        uint amount = r.stake;
+        r.stake = 0;
+        r.whitelisted = false;
+        slashed += amount;
+        emit Slashed(relayer, amount, status);
+        emit RelayerRemoved(relayer);
+        onSlashed(relayer, amount);
+    }
+
+    // hook for registries that reward reporters or forward the slashed stake elsewhere
+    function onSlashed(address relayer, uint amount) internal virtual {}
+}
@@ -0,0 +1,108 @@
+// SPDX-License-Identifier: UNLICENSED
+pragma solidity >0.8.0;
+
+import "./HeaderCodec.sol";
+import "./MPTVerify.sol";
+import "./RLPReader.sol";
+
+// the part of LightNode that consumers depend on
+interface ILightNode {
+    function isHeaderVerified(uint number, bytes32 hash) external view returns (bool);
+    function proveHeaderBetweenCheckpoints(bytes[] memory segment) external view returns (uint number, bytes32 hash);
+}
+
+// one-call reads of MAP chain data for consumer contracts.
+// every read starts from the rlp header headers[0]. the light node stores only checkpoints and
+// the head, so a pruned header is followed by its descendants up to a stored one, which the
+// light node links by parent hash. a stored header comes alone. the light node hashes them, by
+// the extra layout its fork schedule has at their height. istanbul headers are final once
+// sealed so no extra confirmations are needed. the proofs are checked against the roots of
+// headers[0], never against roots supplied by the caller.
+library VerifiedReads {
+    using RLPReader for bytes;
+    using RLPReader for RLPReader.RLPItem;
+
+    struct Log {
+        address emitter;
+        bytes32[] topics;
+        bytes data;
+    }
+
+    function verifiedHeader(ILightNode node, bytes[] memory headers) internal view returns (HeaderCodec.Header memory h, bytes32 hash) {
+        require(headers.length > 0, 'empty header segment');
+        h = HeaderCodec.decode(headers[0]);
+        (, hash) = node.proveHeaderBetweenCheckpoints(headers);
+    }
+
+    // reverts unless the receipt at index of the header is included and has status 1
+    function requireTxSucceeded(
+        ILightNode node, bytes[] memory headers, uint index, bytes memory receipt, bytes[] memory proof
+    ) internal view {
+        (HeaderCodec.Header memory h, ) = verifiedHeader(node, headers);
+        require(MPTVerify.verifyReceipt(h.receiptHash, index, receipt, proof), 'bad receipt proof');
+        require(receiptFields(receipt)[0].toUint() == 1, 'tx failed');
+    }
+
+    // reverts unless the transaction at index of the header is included, returns its hash.
+    // inclusion says nothing about success, pair it with requireTxSucceeded for that
+    function requireTxIncluded(
+        ILightNode node, bytes[] memory headers, uint index, bytes memory transaction, bytes[] memory proof
+    ) internal view returns (bytes32) {
+        (HeaderCodec.Header memory h, ) = verifiedHeader(node, headers);
+        require(MPTVerify.verifyTransaction(h.txHash, index, transaction, proof), 'bad transaction proof');
+        return keccak256(transaction);
+    }
+
+    // log logIndex of the receipt at index. each log can be read once per consumed mapping,
+    // so a relayed event cannot be replayed against the consumer
+    function readEventOnce(
+        mapping(bytes32 => bool) storage consumed,
+        ILightNode node,
+        bytes[] memory headers,
+        uint index,
+        bytes memory receipt,
+        bytes[] memory proof,
+        uint logIndex
+    ) internal returns (Log memory log) {
+        (HeaderCodec.Header memory h, bytes32 hash) = verifiedHeader(node, headers);
+        require(MPTVerify.verifyReceipt(h.receiptHash, index, receipt, proof), 'bad receipt proof');
+
+        bytes32 id = keccak256(abi.encodePacked(hash, index, logIndex));
+        require(!consumed[id], 'event already read');
+        consumed[id] = true;
+
+        RLPReader.RLPItem[] memory logs = receiptFields(receipt)[3].toList();
+        require(logIndex < logs.length, 'bad log index');
+
+        // [address, [topic, ...], data]
+        RLPReader.RLPItem[] memory fields = logs[logIndex].toList();
+        require(fields.length == 3, 'bad receipt log');
+        log.emitter = fields[0].toAddress();
+        RLPReader.RLPItem[] memory topics = fields[1].toList();
+        log.topics = new bytes32[](topics.length);
+        for (uint i = 0; i < topics.length; i++) log.topics[i] = topics[i].toBytes32();
+        log.data = fields[2].toBytes();
+    }
+
+    // value of slot in the storage of account at the header's state root, zero when absent
+    function readStorageAt(
+        ILightNode node,
+        bytes[] memory headers,
+        address account,
+        bytes32 slot,
+        bytes[] memory accountProof,
+        bytes[] memory storageProof
+    ) internal view returns (uint) {
+        (HeaderCodec.Header memory h, ) = verifiedHeader(node, headers);
+        return MPTVerify.getStorage(h.root, account, slot, accountProof, storageProof);
+    }
+
+    // [status, cumulativeGasUsed, bloom, logs], typed receipts are type || rlp
+    function receiptFields(bytes memory receipt) private pure returns (RLPReader.RLPItem[] memory fields) {
+        require(receipt.length > 0, 'bad receipt');
+
+        uint offset = uint8(receipt[0]) < 0x80 ? 1 : 0;
+        fields = receipt.toRlpItem(offset).toList();
+        require(fields.length == 4, 'bad receipt');
+    }
+}
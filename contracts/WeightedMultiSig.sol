@@ -2,6 +2,7 @@
 pragma solidity >0.8.0;
 
 import "./BGLS.sol";
+import "./BN256G1.sol";
 
 // weights:
 // 100 validator: \sum 67 =  \sum 100 - \sum 33
@@ -14,20 +15,32 @@ contract WeightedMultiSig is BGLS {
     uint[] public weights; // voting power
     uint public threshold; // bft, > 2/3,  if  \sum weights = 100, threshold = 67
 
+    uint public constant MAX_VALIDATORS = 256; // bounds every loop over the validator set
+    // stake weights are capped so that the quorum sum of MAX_VALIDATORS weights
+    // stays below 2^136 and can never overflow the 256-bit accumulator
+    uint public constant MAX_WEIGHT = 2 ** 128;
+
     constructor(uint _threshold, G1[] memory _pairKeys, uint[] memory _weights) {
         setStateInternal(_threshold, _pairKeys, _weights);
     }
 
     function setStateInternal(uint _threshold, G1[] memory _pairKeys, uint[] memory _weights) internal {
         require(_pairKeys.length == _weights.length, 'mismatch arg');
+        require(_pairKeys.length <= MAX_VALIDATORS, 'too many validators');
 
-        for (uint i = 0; i < _pairKeys.length; i++) pairKeys.push(_pairKeys[i]);
+        for (uint i = 0; i < _pairKeys.length; i++) {
+            require(_weights[i] <= MAX_WEIGHT, 'weight too large');
+            require(BN256G1.isOnCurveG1(_pairKeys[i]), 'invalid G1 point');
+            pairKeys.push(_pairKeys[i]);
+        }
 
         weights = _weights;
         threshold = _threshold;
     }
 
     function isQuorum(bytes memory bits) public view returns (bool) {
+        require(bits.length == (weights.length + 7) / 8, 'bad bits length');
+
         uint weight = 0;
         for (uint i = 0; i < weights.length; i++) {
             if (chkBit(bits, i)) weight += weights[i];
@@ -54,6 +67,7 @@ contract WeightedMultiSig is BGLS {
     // e(g1, (s+t)*g2) = e(g1, g2)^(s+t)
     //---------------------------------------------------------------
     function checkAggPk(bytes memory bits, G2 memory aggPk) public returns (bool) {
+        require(bits.length == (pairKeys.length + 7) / 8, 'bad bits length');
         return pairingCheck(sumPoints(pairKeys, bits), g2, g1, aggPk);
     }
 
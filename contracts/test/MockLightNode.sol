@@ -0,0 +1,44 @@
+// SPDX-License-Identifier: UNLICENSED
+pragma solidity >0.8.0;
+
+import "../HeaderCodec.sol";
+import "../VerifiedReads.sol";
+
+// stands in for LightNode in the js tests, any header can be marked verified
+contract MockLightNode is ILightNode {
+    mapping(uint => bytes32) hashes;
+    // the extra layout of every header, the atlas one unless set
+    IstanbulExtra.Layout layout = IstanbulExtra.Layout(IstanbulExtra.VANITY, true);
+
+    function setLayout(IstanbulExtra.Layout memory _layout) public {
+        layout = _layout;
+    }
+
+    function hashOf(bytes memory rlpHeader) public view returns (bytes32) {
+        return HeaderCodec.hash(HeaderCodec.decode(rlpHeader), layout);
+    }
+
+    function setHeader(uint number, bytes32 hash) public {
+        hashes[number] = hash;
+    }
+
+    function isHeaderVerified(uint number, bytes32 hash) public view override returns (bool) {
+        return hash != bytes32(0) && hashes[number] == hash;
+    }
+
+    // as LightNode, with the headers hashed by one layout instead of a fork schedule
+    function proveHeaderBetweenCheckpoints(bytes[] memory segment) external view override returns (uint number, bytes32 hash) {
+        require(segment.length > 0, 'empty header segment');
+
+        uint prevNumber;
+        bytes32 prevHash;
+        for (uint i = 0; i < segment.length; i++) {
+            HeaderCodec.Header memory h = HeaderCodec.decode(segment[i]);
+            require(i == 0 || (h.number == prevNumber + 1 && h.parentHash == prevHash), 'broken header segment');
+            prevNumber = h.number;
+            prevHash = HeaderCodec.hash(h, layout);
+            if (i == 0) (number, hash) = (prevNumber, prevHash);
+        }
+        require(isHeaderVerified(prevNumber, prevHash), 'header segment not anchored');
+    }
+}
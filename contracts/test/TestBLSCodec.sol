@@ -0,0 +1,22 @@
+// SPDX-License-Identifier: UNLICENSED
+pragma solidity >0.8.0;
+
+import "../BLSCodec.sol";
+
+contract TestBLSCodec {
+    function encodeG1(BGLS.G1 memory p) public pure returns (bytes memory) {
+        return BLSCodec.encodeG1(p);
+    }
+
+    function decodeG1(bytes memory data) public pure returns (BGLS.G1 memory) {
+        return BLSCodec.decodeG1(data);
+    }
+
+    function encodeG2(BGLS.G2 memory p) public pure returns (bytes memory) {
+        return BLSCodec.encodeG2(p);
+    }
+
+    function decodeG2(bytes memory data) public view returns (BGLS.G2 memory) {
+        return BLSCodec.decodeG2(data);
+    }
+}
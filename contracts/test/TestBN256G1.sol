@@ -0,0 +1,38 @@
+// SPDX-License-Identifier: UNLICENSED
+pragma solidity >0.8.0;
+
+import "../BN256G1.sol";
+
+contract TestBN256G1 {
+    function compress(BGLS.G1 memory a) public pure returns (uint) {
+        return BN256G1.compress(a);
+    }
+
+    function decompress(uint c) public view returns (BGLS.G1 memory) {
+        return BN256G1.decompress(c);
+    }
+
+    function decompressPure(uint c) public pure returns (BGLS.G1 memory) {
+        return BN256G1.decompressPure(c);
+    }
+
+    function fpSqrt(uint a) public view returns (bool, uint) {
+        return BN256G1.fpSqrt(a);
+    }
+
+    function sqrtMod(uint a, uint p) public pure returns (bool, uint) {
+        return BN256G1.sqrtMod(a, p);
+    }
+
+    function isOnCurveG1(BGLS.G1 memory a) public pure returns (bool) {
+        return BN256G1.isOnCurveG1(a);
+    }
+
+    function mulGen(uint scalar) public view returns (BGLS.G1 memory) {
+        return BN256G1.mulGen(scalar);
+    }
+
+    function msm(BGLS.G1[] memory points, uint[] memory scalars) public view returns (BGLS.G1 memory) {
+        return BN256G1.msm(points, scalars);
+    }
+}
@@ -0,0 +1,34 @@
+// SPDX-License-Identifier: UNLICENSED
+pragma solidity >0.8.0;
+
+import "../BN256G2.sol";
+
+contract TestBN256G2 {
+    function addPoints(BGLS.G2 memory a, BGLS.G2 memory b) public view returns (BGLS.G2 memory) {
+        return BN256G2.addPoints(a, b);
+    }
+
+    function doublePoint(BGLS.G2 memory a) public view returns (BGLS.G2 memory) {
+        return BN256G2.doublePoint(a);
+    }
+
+    function scalarMultiply(BGLS.G2 memory a, uint scalar) public view returns (BGLS.G2 memory) {
+        return BN256G2.scalarMultiply(a, scalar);
+    }
+
+    function compress(BGLS.G2 memory a) public pure returns (uint, uint) {
+        return BN256G2.compress(a);
+    }
+
+    function decompress(uint xr, uint xi) public view returns (BGLS.G2 memory) {
+        return BN256G2.decompress(xr, xi);
+    }
+
+    function isOnCurveG2(BGLS.G2 memory a) public pure returns (bool) {
+        return BN256G2.isOnCurveG2(a);
+    }
+
+    function isInSubgroupG2(BGLS.G2 memory a) public view returns (bool) {
+        return BN256G2.isInSubgroupG2(a);
+    }
+}
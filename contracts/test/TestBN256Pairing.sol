@@ -0,0 +1,10 @@
+// SPDX-License-Identifier: UNLICENSED
+pragma solidity >0.8.0;
+
+import "../BN256Pairing.sol";
+
+contract TestBN256Pairing {
+    function checkBatch(BGLS.G1[] memory a, BGLS.G2[] memory b) public view returns (bool) {
+        return BN256Pairing.checkBatch(a, b);
+    }
+}
@@ -0,0 +1,26 @@
+// SPDX-License-Identifier: UNLICENSED
+pragma solidity >0.8.0;
+
+import "../Bitmap.sol";
+
+contract TestBitmap {
+    function isBitSet(uint[] memory bitmap, uint i) public pure returns (bool) {
+        return Bitmap.isBitSet(bitmap, i);
+    }
+
+    function countSetBits(uint[] memory bitmap) public pure returns (uint) {
+        return Bitmap.countSetBits(bitmap);
+    }
+
+    function fitsIn(uint[] memory bitmap, uint n) public pure returns (bool) {
+        return Bitmap.fitsIn(bitmap, n);
+    }
+
+    function nextSetBit(uint[] memory bitmap, uint from) public pure returns (uint) {
+        return Bitmap.nextSetBit(bitmap, from);
+    }
+
+    function setBits(uint[] memory bitmap) public pure returns (uint[] memory) {
+        return Bitmap.setBits(bitmap);
+    }
+}
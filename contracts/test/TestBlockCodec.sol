@@ -0,0 +1,18 @@
+// SPDX-License-Identifier: UNLICENSED
+pragma solidity >0.8.0;
+
+import "../BlockCodec.sol";
+
+contract TestBlockCodec {
+    function decode(bytes memory data) public pure returns (BlockCodec.Block memory) {
+        return BlockCodec.decode(data);
+    }
+
+    function headerRlp(bytes memory data) public pure returns (bytes memory) {
+        return BlockCodec.headerRlp(data);
+    }
+
+    function epochSnarkData(bytes memory data) public pure returns (BlockCodec.EpochSnarkData memory) {
+        return BlockCodec.epochSnarkData(data);
+    }
+}
@@ -0,0 +1,22 @@
+// SPDX-License-Identifier: UNLICENSED
+pragma solidity >0.8.0;
+
+import "../EthHeaderCodec.sol";
+
+contract TestEthHeaderCodec {
+    function encode(EthHeaderCodec.EthHeader memory h) public pure returns (bytes memory) {
+        return EthHeaderCodec.encode(h);
+    }
+
+    function decode(bytes memory data) public pure returns (EthHeaderCodec.EthHeader memory) {
+        return EthHeaderCodec.decode(data);
+    }
+
+    function hash(EthHeaderCodec.EthHeader memory h) public pure returns (bytes32) {
+        return EthHeaderCodec.hash(h);
+    }
+
+    function isPostMerge(EthHeaderCodec.EthHeader memory h) public pure returns (bool) {
+        return EthHeaderCodec.isPostMerge(h);
+    }
+}
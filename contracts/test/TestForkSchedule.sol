@@ -0,0 +1,20 @@
+// SPDX-License-Identifier: UNLICENSED
+pragma solidity >0.8.0;
+
+import "../ForkSchedule.sol";
+
+contract TestForkSchedule {
+    function validate(ForkSchedule.Schedule memory s) public pure {
+        ForkSchedule.validate(s);
+    }
+
+    function layoutAt(ForkSchedule.Schedule memory s, uint number) public pure returns (IstanbulExtra.Layout memory) {
+        ForkSchedule.validate(s);
+        return ForkSchedule.layoutAt(s, number);
+    }
+
+    function hash(ForkSchedule.Schedule memory s, bytes memory rlpHeader) public pure returns (bytes32) {
+        ForkSchedule.validate(s);
+        return ForkSchedule.hash(s, HeaderCodec.decode(rlpHeader));
+    }
+}
@@ -0,0 +1,35 @@
+// SPDX-License-Identifier: UNLICENSED
+pragma solidity >0.8.0;
+
+import "../CompactHeader.sol";
+import "../HeaderCodec.sol";
+
+contract TestHeaderCodec {
+    function encode(HeaderCodec.Header memory h) public pure returns (bytes memory) {
+        return HeaderCodec.encode(h);
+    }
+
+    function decode(bytes memory data) public pure returns (HeaderCodec.Header memory) {
+        return HeaderCodec.decode(data);
+    }
+
+    function decodeCompact(bytes memory data) public pure returns (HeaderCodec.Header memory) {
+        return CompactHeader.decode(data);
+    }
+
+    function hash(HeaderCodec.Header memory h) public pure returns (bytes32) {
+        return HeaderCodec.hash(h);
+    }
+
+    function hashHeaderForSeal(HeaderCodec.Header memory h) public pure returns (bytes32) {
+        return HeaderCodec.hashHeaderForSeal(h);
+    }
+
+    function committedSealMessage(HeaderCodec.Header memory h, uint round) public pure returns (bytes memory) {
+        return HeaderCodec.committedSealMessage(h, round);
+    }
+
+    function filterExtra(bytes memory extra, bool keepSeal) public pure returns (bytes memory) {
+        return IstanbulExtra.filter(extra, keepSeal);
+    }
+}
@@ -0,0 +1,28 @@
+// SPDX-License-Identifier: UNLICENSED
+pragma solidity >0.8.0;
+
+import "../HeaderStore.sol";
+
+contract TestHeaderStore {
+    using HeaderStore for HeaderStore.Store;
+
+    HeaderStore.Store store;
+
+    event Committed(uint number, bytes32 hash, bool added);
+
+    function commit(uint number, bytes32 hash) public {
+        emit Committed(number, hash, store.commit(number, hash));
+    }
+
+    function headerHashByNumber(uint number) public view returns (bytes32) {
+        return store.headerHashByNumber(number);
+    }
+
+    function headerNumberByHash(bytes32 hash) public view returns (bool, uint) {
+        return store.headerNumberByHash(hash);
+    }
+
+    function isCommitted(uint number, bytes32 hash) public view returns (bool) {
+        return store.isCommitted(number, hash);
+    }
+}
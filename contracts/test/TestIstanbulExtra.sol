@@ -0,0 +1,20 @@
+// SPDX-License-Identifier: UNLICENSED
+pragma solidity >0.8.0;
+
+import "../IstanbulExtra.sol";
+
+contract TestIstanbulExtra {
+    function decode(bytes memory extra) public pure returns (IstanbulExtra.Extra memory) {
+        return IstanbulExtra.decode(extra);
+    }
+
+    function decodeWithLayout(bytes memory extra, IstanbulExtra.Layout memory layout) public pure returns (IstanbulExtra.Extra memory) {
+        IstanbulExtra.validateLayout(layout);
+        return IstanbulExtra.decode(extra, layout);
+    }
+
+    function filter(bytes memory extra, bool keepSeal, IstanbulExtra.Layout memory layout) public pure returns (bytes memory) {
+        IstanbulExtra.validateLayout(layout);
+        return IstanbulExtra.filter(extra, keepSeal, layout);
+    }
+}
@@ -0,0 +1,16 @@
+// SPDX-License-Identifier: UNLICENSED
+pragma solidity >0.8.0;
+
+import "../LightNodeProof.sol";
+
+contract TestLightNodeProof {
+    function headerHashSlot(uint number) public pure returns (bytes32) {
+        return LightNodeProof.headerHashSlot(number);
+    }
+
+    function verifyHeaderHash(
+        bytes32 stateRoot, address lightNode, uint number, bytes[] memory accountProof, bytes[] memory storageProof
+    ) public pure returns (bytes32) {
+        return LightNodeProof.verifyHeaderHash(stateRoot, lightNode, number, accountProof, storageProof);
+    }
+}
@@ -0,0 +1,24 @@
+// SPDX-License-Identifier: UNLICENSED
+pragma solidity >0.8.0;
+
+import "../MPTVerify.sol";
+
+contract TestMPTVerify {
+    function get(bytes32 root, bytes memory key, bytes[] memory proof) public pure returns (bool found, bytes memory value) {
+        return MPTVerify.get(root, key, proof);
+    }
+
+    function verifyReceipt(bytes32 receiptsRoot, uint index, bytes memory receipt, bytes[] memory proof) public pure returns (bool) {
+        return MPTVerify.verifyReceipt(receiptsRoot, index, receipt, proof);
+    }
+
+    function verifyTransaction(bytes32 txRoot, uint index, bytes memory transaction, bytes[] memory proof) public pure returns (bool) {
+        return MPTVerify.verifyTransaction(txRoot, index, transaction, proof);
+    }
+
+    function verifyReceipts(
+        bytes32 receiptsRoot, uint[] memory indices, bytes[] memory receipts, MPTVerify.ProofBundle memory bundle
+    ) public pure returns (bool) {
+        return MPTVerify.verifyReceipts(receiptsRoot, indices, receipts, bundle);
+    }
+}
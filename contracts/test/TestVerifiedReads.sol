@@ -0,0 +1,34 @@
+// SPDX-License-Identifier: UNLICENSED
+pragma solidity >0.8.0;
+
+import "../VerifiedReads.sol";
+
+contract TestVerifiedReads {
+    ILightNode node;
+    mapping(bytes32 => bool) consumed;
+
+    event EventRead(address emitter, bytes32[] topics, bytes data);
+
+    constructor(ILightNode _node) {
+        node = _node;
+    }
+
+    function requireTxSucceeded(bytes[] memory headers, uint index, bytes memory receipt, bytes[] memory proof) public view {
+        VerifiedReads.requireTxSucceeded(node, headers, index, receipt, proof);
+    }
+
+    function requireTxIncluded(bytes[] memory headers, uint index, bytes memory transaction, bytes[] memory proof) public view returns (bytes32) {
+        return VerifiedReads.requireTxIncluded(node, headers, index, transaction, proof);
+    }
+
+    function readEventOnce(bytes[] memory headers, uint index, bytes memory receipt, bytes[] memory proof, uint logIndex) public {
+        VerifiedReads.Log memory log = VerifiedReads.readEventOnce(consumed, node, headers, index, receipt, proof, logIndex);
+        emit EventRead(log.emitter, log.topics, log.data);
+    }
+
+    function readStorageAt(
+        bytes[] memory headers, address account, bytes32 slot, bytes[] memory accountProof, bytes[] memory storageProof
+    ) public view returns (uint) {
+        return VerifiedReads.readStorageAt(node, headers, account, slot, accountProof, storageProof);
+    }
+}
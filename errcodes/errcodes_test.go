@@ -0,0 +1,60 @@
+package errcodes
+
+import (
+	"encoding/hex"
+	"math/big"
+	"testing"
+)
+
+// abi encoding of Error(reason)
+func revertData(reason string) []byte {
+	data := append([]byte{}, errorSelector...)
+	data = append(data, word(32)...)
+	data = append(data, word(uint64(len(reason)))...)
+	padded := make([]byte, (len(reason)+31)/32*32)
+	copy(padded, reason)
+	return append(data, padded...)
+}
+
+func word(v uint64) []byte {
+	return new(big.Int).SetUint64(v).FillBytes(make([]byte, 32))
+}
+
+func TestCodeOf(t *testing.T) {
+	for reason, want := range ByReason {
+		code, ok := CodeOf(revertData(reason))
+		if !ok || code != want {
+			t.Errorf("CodeOf(%q) = %d, %v, want %d", reason, code, ok, want)
+		}
+	}
+
+	// the revert of TestBN256G1.msm with mismatched inputs, as ethers returns it
+	data, _ := hex.DecodeString("08c379a0" +
+		"0000000000000000000000000000000000000000000000000000000000000020" +
+		"0000000000000000000000000000000000000000000000000000000000000012" +
+		"6d69736d61746368206d736d20696e7075740000000000000000000000000000")
+	if code, ok := CodeOf(data); !ok || code != MismatchMsmInput {
+		t.Errorf("CodeOf(msm revert) = %d, %v, want %d", code, ok, MismatchMsmInput)
+	}
+}
+
+func TestCodeOfRejects(t *testing.T) {
+	panicData, _ := hex.DecodeString("4e487b71" + "0000000000000000000000000000000000000000000000000000000000000011")
+	badOffset := revertData("bad seal bitmap")
+	copy(badOffset[4:36], word(1<<40))
+	badLength := revertData("bad seal bitmap")
+	copy(badLength[36:68], word(1<<40))
+
+	for name, data := range map[string][]byte{
+		"empty":        nil,
+		"panic":        panicData,
+		"unregistered": revertData("brand new failure"),
+		"short":        revertData("bad seal bitmap")[:40],
+		"bad offset":   badOffset,
+		"bad length":   badLength,
+	} {
+		if code, ok := CodeOf(data); ok {
+			t.Errorf("CodeOf(%s) = %d, want no code", name, code)
+		}
+	}
+}
@@ -0,0 +1,153 @@
+// Checks the revert reasons of the contracts against the error code registry in errors.json.
+//
+//   node scripts/error-codes.js             fails on reasons missing from the registry
+//   node scripts/error-codes.js --go FILE   also writes the registry as go constants
+//
+// errcodes/errcodes.go is that go source, regenerate it with --go errcodes/errcodes.go
+// whenever errors.json changes.
+//
+// Codes are never reused or renumbered: a new failure cause gets the next free code of
+// its group (1xx curve and signatures, 2xx rlp and headers, 3xx proofs, 4xx light client),
+// so monitoring can aggregate rejections by code across contract versions.
+//
+// The contracts revert with Error(string), not with custom errors that carry the code.
+// The reasons are part of the interface the tests and relayers check, and changing every
+// revert would change what deployed callers decode. Instead codeOf here, and CodeOf
+// in the generated go, take the raw revert data and return the code, so no caller has to
+// parse the reason itself.
+const fs = require('fs');
+const path = require('path');
+
+// selector of Error(string)
+const ERROR_SELECTOR = '08c379a0';
+
+const ROOT = path.join(__dirname, '..');
+const CONTRACTS = path.join(ROOT, 'contracts');
+const REGISTRY = path.join(ROOT, 'errors.json');
+
+function solidityFiles(dir) {
+    return fs.readdirSync(dir, {withFileTypes: true}).flatMap(e => {
+        const file = path.join(dir, e.name);
+        // the test harnesses do not revert on their own
+        if (e.isDirectory()) return e.name === 'test' ? [] : solidityFiles(file);
+        return e.name.endsWith('.sol') ? [file] : [];
+    });
+}
+
+// reason -> files using it, for every require(..., 'reason') and revert('reason')
+function contractReasons() {
+    const reasons = {};
+    const pattern = /(?:require\([^;]*?,\s*|revert\()'([^']+)'\)/g;
+    for (const file of solidityFiles(CONTRACTS)) {
+        const source = fs.readFileSync(file, 'utf8');
+        for (const m of source.matchAll(pattern)) {
+            (reasons[m[1]] = reasons[m[1]] || new Set()).add(path.relative(ROOT, file));
+        }
+    }
+    return reasons;
+}
+
+function loadRegistry() {
+    return JSON.parse(fs.readFileSync(REGISTRY, 'utf8'));
+}
+
+// problems with the registry or the contracts, empty when they agree
+function check(registry = loadRegistry(), reasons = contractReasons()) {
+    const problems = [];
+    const codes = new Set();
+    const names = new Set();
+    const registered = new Set();
+    for (const e of registry) {
+        if (codes.has(e.code)) problems.push(`duplicate code ${e.code}`);
+        if (names.has(e.name)) problems.push(`duplicate name ${e.name}`);
+        if (registered.has(e.reason)) problems.push(`duplicate reason '${e.reason}'`);
+        codes.add(e.code);
+        names.add(e.name);
+        registered.add(e.reason);
+    }
+    for (const [reason, files] of Object.entries(reasons)) {
+        if (!registered.has(reason)) problems.push(`'${reason}' in ${[...files].join(', ')} has no code`);
+    }
+    return problems;
+}
+
+// the code of the Error(string) in revert data given as hex, undefined for anything else
+function codeOf(data, registry = loadRegistry()) {
+    const hex = data.replace(/^0x/, '');
+    if (hex.slice(0, 8) !== ERROR_SELECTOR || hex.length < 8 + 128) return undefined;
+    const offset = parseInt(hex.slice(8, 72), 16);
+    const start = 8 + 2 * offset;
+    const length = parseInt(hex.slice(start, start + 64), 16);
+    const reason = Buffer.from(hex.slice(start + 64, start + 64 + 2 * length), 'hex').toString('utf8');
+    const entry = registry.find(e => e.reason === reason);
+    return entry && entry.code;
+}
+
+// aligned the way gofmt does it
+function goSource(registry) {
+    const nameWidth = Math.max(...registry.map(e => goName(e.name).length));
+    const reasonWidth = Math.max(...registry.map(e => JSON.stringify(e.reason).length + 1));
+    const lines = [
+        '// Code generated by scripts/error-codes.js from errors.json. DO NOT EDIT.',
+        '',
+        'package errcodes',
+        '',
+        'import (',
+        '\t"bytes"',
+        '\t"math/big"',
+        ')',
+        '',
+        'const (',
+        ...registry.map(e => `\t${goName(e.name).padEnd(nameWidth)} = ${e.code}`),
+        ')',
+        '',
+        '// ByReason maps a contract revert reason to its code.',
+        'var ByReason = map[string]int{',
+        ...registry.map(e => `\t${(JSON.stringify(e.reason) + ':').padEnd(reasonWidth)} ${goName(e.name)},`),
+        '}',
+        '',
+        `var errorSelector = []byte{${ERROR_SELECTOR.match(/../g).map(b => '0x' + b).join(', ')}}`,
+        '',
+        '// CodeOf returns the code of the Error(string) a contract reverted with, given the',
+        '// revert data. ok is false for other revert data and for unregistered reasons.',
+        'func CodeOf(data []byte) (code int, ok bool) {',
+        '\tif len(data) < 4+64 || !bytes.Equal(data[:4], errorSelector) {',
+        '\t\treturn 0, false',
+        '\t}',
+        '\targs := data[4:]',
+        '\toffset := new(big.Int).SetBytes(args[:32])',
+        '\tif !offset.IsUint64() || offset.Uint64() > uint64(len(args)-32) {',
+        '\t\treturn 0, false',
+        '\t}',
+        '\tlength := new(big.Int).SetBytes(args[offset.Uint64() : offset.Uint64()+32])',
+        '\tstart := offset.Uint64() + 32',
+        '\tif !length.IsUint64() || length.Uint64() > uint64(len(args))-start {',
+        '\t\treturn 0, false',
+        '\t}',
+        '\tcode, ok = ByReason[string(args[start:start+length.Uint64()])]',
+        '\treturn code, ok',
+        '}',
+        '',
+    ];
+    return lines.join('\n');
+}
+
+// INVALID_G1_POINT -> InvalidG1Point
+function goName(name) {
+    return name.toLowerCase().split('_').map(w => w.charAt(0).toUpperCase() + w.slice(1)).join('');
+}
+
+function main() {
+    const registry = loadRegistry();
+    const problems = check(registry);
+    problems.forEach(p => console.error(p));
+
+    const goFlag = process.argv.indexOf('--go');
+    if (goFlag !== -1) fs.writeFileSync(process.argv[goFlag + 1], goSource(registry));
+
+    process.exit(problems.length === 0 ? 0 : 1);
+}
+
+if (require.main === module) main();
+
+module.exports = {contractReasons, loadRegistry, check, codeOf, goSource};
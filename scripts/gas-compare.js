@@ -0,0 +1,76 @@
+// Compares the gas table of two git revisions.
+//
+//   node scripts/gas-compare.js <base-rev> <head-rev> [threshold-percent]
+//
+// Each revision is checked out into a temporary worktree sharing this checkout's
+// node_modules, compiled, and measured with scripts/gas-table.js of that revision.
+// Inputs of the gas table are random keys, so small differences are noise: only
+// changes above the threshold (default 1%) are flagged.
+const fs = require('fs');
+const os = require('os');
+const path = require('path');
+const {execFileSync} = require('child_process');
+
+const ROOT = path.join(__dirname, '..');
+
+function run(cmd, args, cwd) {
+    execFileSync(cmd, args, {cwd, stdio: ['ignore', 'ignore', 'inherit']});
+}
+
+function measure(rev) {
+    const dir = fs.mkdtempSync(path.join(os.tmpdir(), 'gas-compare-'));
+    run('git', ['worktree', 'add', '--detach', dir, rev], ROOT);
+    try {
+        if (!fs.existsSync(path.join(dir, 'scripts', 'gas-table.js'))) {
+            throw new Error(`${rev} has no scripts/gas-table.js`);
+        }
+        fs.symlinkSync(path.join(ROOT, 'node_modules'), path.join(dir, 'node_modules'), 'dir');
+        run('npx', ['hardhat', 'run', 'scripts/gas-table.js'], dir);
+        return JSON.parse(fs.readFileSync(path.join(dir, 'gas-table.json'), 'utf8'));
+    } finally {
+        run('git', ['worktree', 'remove', '--force', dir], ROOT);
+    }
+}
+
+// flattens {contract: {function: {size: gas}}} into 'contract.function[size]' -> gas
+function flatten(table) {
+    const rows = {};
+    for (const [contract, fns] of Object.entries(table)) {
+        for (const [fn, sizes] of Object.entries(fns)) {
+            for (const [size, gas] of Object.entries(sizes)) rows[`${contract}.${fn}[${size}]`] = gas;
+        }
+    }
+    return rows;
+}
+
+function compare(base, head, threshold) {
+    const a = flatten(base);
+    const b = flatten(head);
+    const keys = [...new Set([...Object.keys(a), ...Object.keys(b)])].sort();
+
+    const lines = [['function', 'base', 'head', 'delta', ''].join('\t')];
+    for (const key of keys) {
+        if (a[key] === undefined || b[key] === undefined) {
+            lines.push([key, a[key] ?? '-', b[key] ?? '-', '', a[key] === undefined ? 'added' : 'removed'].join('\t'));
+            continue;
+        }
+        const delta = (b[key] - a[key]) / a[key] * 100;
+        const flag = Math.abs(delta) > threshold ? (delta < 0 ? 'better' : 'worse') : '';
+        lines.push([key, a[key], b[key], delta.toFixed(2) + '%', flag].join('\t'));
+    }
+    return lines.join('\n');
+}
+
+function main() {
+    const [baseRev, headRev, threshold = '1'] = process.argv.slice(2);
+    if (!baseRev || !headRev) {
+        console.error('usage: node scripts/gas-compare.js <base-rev> <head-rev> [threshold-percent]');
+        process.exit(2);
+    }
+
+    const base = measure(baseRev);
+    const head = measure(headRev);
+    console.log(compare(base, head, Number(threshold)));
+}
+
+main();
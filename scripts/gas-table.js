@@ -0,0 +1,186 @@
+// Measures the gas used by the public verifier functions for a few representative
+// validator set sizes and writes the result to gas-table.json.
+//
+//   npx hardhat run scripts/gas-table.js
+//
+// The table is keyed by contract, then function, then input size, so tooling can
+// look up the closest measured size when budgeting submissions.
+const fs = require('fs');
+const path = require('path');
+const hre = require('hardhat');
+const bls254 = require('../test/blsbn254');
+const {BigNumber} = require("ethers");
+const {SEAL_DST, chainConfig, encodeHeader, encodeCompactHeader, headerHash, randomHeader, encodeExtra, committedSealMessage} = require('../test/header');
+const {convertG1, convertG2, lightNodeFactory} = require('../test/helpers');
+
+const SIZES = [4, 16, 64, 128];
+const MESSAGE = '0x6162636566676869';
+const OUTPUT = path.join(__dirname, '..', 'gas-table.json');
+
+// bitmap with every validator set
+function fullBits(n) {
+    const bytes = new Uint8Array((n + 7) >> 3);
+    for (let i = 0; i < n; i++) bytes[i >> 3] |= 1 << (i % 8);
+    return hre.ethers.utils.hexlify(bytes);
+}
+
+async function deploy(name, ...args) {
+    const factory = await hre.ethers.getContractFactory(name);
+    const contract = await factory.deploy(...args);
+    await contract.deployed();
+    return contract;
+}
+
+async function measureBGLS() {
+    const bgls = await deploy('BGLS');
+    const key = bls254.newKeyPair();
+    const sig = bls254.sign(MESSAGE, key.secret).signature;
+
+    return {
+        hashToG1: {1: (await bgls.estimateGas.hashToG1(MESSAGE)).toNumber()},
+        checkSignature: {1: (await bgls.estimateGas.checkSignature(MESSAGE, convertG1(sig), convertG2(key.pubkey))).toNumber()},
+    };
+}
+
+async function measureWeightedMultiSig() {
+    const table = {isQuorum: {}, checkAggPk: {}, checkSig: {}};
+
+    for (const n of SIZES) {
+        const keys = Array.from({length: n}, () => bls254.newKeyPair());
+        const pkG1s = keys.map(k => convertG1(bls254.g1Mul(k.secret, bls254.g1())));
+        const wms = await deploy('WeightedMultiSig', n, pkG1s, Array(n).fill(1));
+
+        let aggPk = keys[0].pubkey;
+        let aggSig = bls254.sign(MESSAGE, keys[0].secret).signature;
+        for (let i = 1; i < n; i++) {
+            aggPk = bls254.aggreagate(aggPk, keys[i].pubkey);
+            aggSig = bls254.aggreagate(aggSig, bls254.sign(MESSAGE, keys[i].secret).signature);
+        }
+
+        const bits = fullBits(n);
+        table.isQuorum[n] = (await wms.estimateGas.isQuorum(bits)).toNumber();
+        table.checkAggPk[n] = (await wms.estimateGas.checkAggPk(bits, convertG2(aggPk))).toNumber();
+        table.checkSig[n] = (await wms.estimateGas.checkSig(bits, MESSAGE, convertG1(aggSig), convertG2(aggPk))).toNumber();
+    }
+    return table;
+}
+
+async function measureBN256G2() {
+    const g2 = await deploy('TestBN256G2');
+    const p = convertG2(bls254.randG2());
+    const q = convertG2(bls254.randG2());
+    const k = BigNumber.from(bls254.mclToHex(bls254.randFr()));
+
+    return {
+        addPoints: {1: (await g2.estimateGas.addPoints(p, q)).toNumber()},
+        doublePoint: {1: (await g2.estimateGas.doublePoint(p)).toNumber()},
+        scalarMultiply: {1: (await g2.estimateGas.scalarMultiply(p, k)).toNumber()},
+    };
+}
+
+// distinct messages: one checkBatch of n + 1 pairs against n checkSignature calls
+async function measureBN256Pairing() {
+    const bgls = await deploy('BGLS');
+    const pairing = await deploy('TestBN256Pairing');
+    const table = {checkBatch: {}, checkSignature: {}};
+
+    for (const n of [10, 50, 100]) {
+        const a = [];
+        const b = [];
+        let aggSig;
+        let separate = 0;
+        for (let i = 0; i < n; i++) {
+            const key = bls254.newKeyPair();
+            const message = bls254.randHex(32);
+            const {signature, M} = bls254.sign(message, key.secret);
+            aggSig = i === 0 ? signature : bls254.aggreagate(aggSig, signature);
+            a.push(convertG1(M));
+            b.push(convertG2(key.pubkey));
+            separate += (await bgls.estimateGas.checkSignature(message, convertG1(signature), convertG2(key.pubkey))).toNumber();
+        }
+        const sig = convertG1(aggSig);
+        a.unshift({x: sig.x, y: bls254.PRIME.sub(sig.y)});
+        b.unshift(convertG2(bls254.g2()));
+
+        table.checkBatch[n] = (await pairing.estimateGas.checkBatch(a, b)).toNumber();
+        table.checkSignature[n] = separate;
+    }
+    return table;
+}
+
+async function measureBN256G1() {
+    const g1 = await deploy('TestBN256G1');
+    const table = {msm: {}};
+
+    for (const n of SIZES) {
+        const points = [];
+        const scalars = [];
+        for (let i = 0; i < n; i++) {
+            points.push(convertG1(bls254.randG1()));
+            scalars.push(BigNumber.from(bls254.mclToHex(bls254.randFr())));
+        }
+        table.msm[n] = (await g1.estimateGas.msm(points, scalars)).toNumber();
+    }
+    return table;
+}
+
+// EIP-2028 cost of data in a transaction
+function calldataGas(data) {
+    return Array.from(hre.ethers.utils.arrayify(data)).reduce((gas, b) => gas + (b === 0 ? 4 : 16), 0);
+}
+
+// one sealed header on top of the trusted one, submitted as rlp and as a compact header.
+// the totals include the intrinsic and calldata gas, the calldata gas is also listed apart.
+// the node is deployed with the script's account as its registry, so it is measured without the
+// registry in front
+async function measureLightNode() {
+    const n = 4;
+    const keys = Array.from({length: n}, () => bls254.newKeyPair());
+    const genesisHash = bls254.randHex(32);
+    const [registry] = await hre.ethers.getSigners();
+    const LightNode = await lightNodeFactory();
+    const node = await LightNode.deploy(chainConfig(100), 0, genesisHash, keys.map(k => convertG2(k.pubkey)), registry.address);
+    await node.deployed();
+
+    const h = randomHeader(true, encodeExtra());
+    h.parentHash = genesisHash;
+    h.number = BigNumber.from(1);
+    const message = committedSealMessage(headerHash(h), 0);
+    let sig = bls254.signWithDST(SEAL_DST, message, keys[0].secret).signature;
+    for (let i = 1; i < n; i++) {
+        sig = bls254.aggreagate(sig, bls254.signWithDST(SEAL_DST, message, keys[i].secret).signature);
+    }
+    h.extra = encodeExtra({aggregatedSeal: {bitmap: (1 << n) - 1, signature: hre.ethers.utils.hexConcat(bls254.g1ToHex(sig)), round: 0}});
+
+    const rlp = encodeHeader(h);
+    const compact = encodeCompactHeader(h);
+    return {
+        submitHeader: {[n]: (await node.estimateGas.submitHeader(rlp, registry.address)).toNumber()},
+        submitCompactHeader: {[n]: (await node.estimateGas.submitCompactHeader(compact, registry.address)).toNumber()},
+        submitHeaderCalldata: {[n]: calldataGas(node.interface.encodeFunctionData('submitHeader', [rlp, registry.address]))},
+        submitCompactHeaderCalldata: {[n]: calldataGas(node.interface.encodeFunctionData('submitCompactHeader', [compact, registry.address]))},
+    };
+}
+
+async function main() {
+    await bls254.init();
+
+    const table = {
+        BGLS: await measureBGLS(),
+        WeightedMultiSig: await measureWeightedMultiSig(),
+        BN256G2: await measureBN256G2(),
+        BN256Pairing: await measureBN256Pairing(),
+        BN256G1: await measureBN256G1(),
+        LightNode: await measureLightNode(),
+    };
+
+    fs.writeFileSync(OUTPUT, JSON.stringify(table, null, 2) + '\n');
+    console.log('gas table written to', OUTPUT);
+}
+
+main()
+  .then(() => process.exit(0))
+  .catch((error) => {
+    console.error(error);
+    process.exit(1);
+  });
@@ -0,0 +1,170 @@
+// Generates test vectors for the curve operations, signatures, header hashes and aggregated seals.
+//
+//   node scripts/gen-vectors.js [--seed SEED] [--count N] [--out FILE]
+//
+// Every value is derived from the seed, so the same seed always gives the same file and
+// fixtures can be regenerated instead of edited by hand. By default it rewrites
+// test/testdata/vectors.json, which test/testVectors.js and the go tests next to it read.
+// Points are hex coordinates, G2 as xr, xi, yr, yi like the BGLS G2 struct; headers are
+// the rlp HeaderCodec decodes.
+const fs = require('fs');
+const path = require('path');
+const mcl = require('mcl-wasm');
+const {ethers} = require('ethers');
+const bls254 = require('../test/blsbn254');
+const {SEAL_DST, encodeHeader, headerHash, sigHash, randomHeader, encodeExtra, committedSealMessage} = require('../test/header');
+
+const {BigNumber} = ethers;
+
+// deterministic bytes: keccak(seed || counter) chained as far as needed
+function seededRandom(seed) {
+    let counter = 0;
+    const randHex = (n) => {
+        let out = '0x';
+        while (ethers.utils.hexDataLength(out) < n) {
+            out = ethers.utils.hexConcat([out, ethers.utils.solidityKeccak256(['string', 'uint'], [seed, counter++])]);
+        }
+        return ethers.utils.hexDataSlice(out, 0, n);
+    };
+    // reduced by hand rather than with mcl's setHashOf, so the go tests can derive the same scalars
+    const randFr = () => {
+        const fr = new mcl.Fr();
+        fr.setStr(BigNumber.from(randHex(32)).mod(bls254.ORDER).toString());
+        return fr;
+    };
+    return {randHex, randFr};
+}
+
+const g1 = (p) => {
+    const hex = bls254.g1ToHex(p);
+    return {x: hex[0], y: hex[1]};
+};
+
+const g2 = (p) => {
+    const hex = bls254.g2ToHex(p);
+    return {xr: hex[0], xi: hex[1], yr: hex[2], yi: hex[3]};
+};
+
+const fr = (k) => bls254.mclToHex(k);
+
+function generate(seed, count) {
+    const {randHex, randFr} = seededRandom(seed);
+    const randG1 = () => bls254.g1Mul(randFr(), bls254.g1());
+    const vectors = {seed, count, g1Add: [], g1Mul: [], signatures: [], headers: [], invalidExtraHeaders: [], aggregatedSeals: []};
+
+    for (let i = 0; i < count; i++) {
+        const a = randG1();
+        const b = randG1();
+        vectors.g1Add.push({a: g1(a), b: g1(b), sum: g1(bls254.aggreagate(a, b))});
+
+        const k = randFr();
+        const p = randG1();
+        vectors.g1Mul.push({point: g1(p), scalar: fr(k), product: g1(bls254.g1Mul(k, p))});
+
+        // signers of one message and their aggregates
+        const message = randHex(32);
+        const signers = [];
+        let aggSig;
+        let aggKey;
+        for (let j = 0; j <= i % 4; j++) {
+            const secret = randFr();
+            const pubkey = bls254.g2Mul(secret, bls254.g2());
+            const {signature} = bls254.sign(message, secret);
+            aggSig = aggSig ? bls254.aggreagate(aggSig, signature) : signature;
+            aggKey = aggKey ? bls254.aggreagate(aggKey, pubkey) : pubkey;
+            signers.push({secret: fr(secret), pubkey: g2(pubkey), signature: g1(signature)});
+        }
+        vectors.signatures.push({message, signers, signature: g1(aggSig), pubkey: g2(aggKey)});
+
+        const round = i % 3;
+        const h = {
+            parentHash: randHex(32),
+            coinbase: ethers.utils.getAddress(randHex(20)),
+            root: randHex(32),
+            txHash: randHex(32),
+            receiptHash: randHex(32),
+            bloom: randHex(256),
+            number: BigNumber.from(randHex(4)),
+            gasLimit: BigNumber.from(randHex(4)),
+            gasUsed: BigNumber.from(randHex(2)),
+            time: BigNumber.from(randHex(4)),
+            extra: encodeExtra({seal: randHex(65), aggregatedSeal: {bitmap: 7, signature: randHex(64), round}}),
+            mixDigest: randHex(32),
+            nonce: randHex(8),
+            hasBaseFee: i % 2 === 1,
+            baseFee: i % 2 === 1 ? BigNumber.from(randHex(6)) : BigNumber.from(0),
+            hasBlobGas: false,
+            blobGasUsed: BigNumber.from(0),
+            excessBlobGas: BigNumber.from(0),
+            hasParentBeaconRoot: false,
+            parentBeaconRoot: ethers.constants.HashZero,
+        };
+        const hash = headerHash(h);
+        vectors.headers.push({
+            rlp: encodeHeader(h), hash, sigHash: sigHash(h), round, committedSealMessage: committedSealMessage(hash, round),
+        });
+    }
+
+    // extras that do not decode as the atlas layout, hashed over the raw encoding and signed as
+    // an empty list: too short,
+    // vanity and a few bytes, the celo layout without the g1 keys and trailing bytes
+    const extras = [
+        () => randHex(32),
+        () => randHex(40),
+        () => ethers.utils.hexConcat([randHex(32), ethers.utils.RLP.encode([[], [], '0x', '0x', ['0x', '0x', '0x'], ['0x', '0x', '0x']])]),
+        () => ethers.utils.hexConcat([encodeExtra(), '0x00']),
+    ];
+    extras.forEach((extra, i) => {
+        const h = randomHeader(i % 2 === 1, extra(), randHex);
+        vectors.invalidExtraHeaders.push({rlp: encodeHeader(h), hash: headerHash(h), sigHash: sigHash(h)});
+    });
+
+    // headers on top of genesis sealed by some of the validators, who sign out of order. bit i of
+    // the bitmap is validator i, more than 8 validators put signers in the second byte
+    for (const signers of [[4, 3, 2, 0], [9, 0, 8, 4, 7, 6, 5]]) {
+        const secrets = Array.from({length: Math.max(...signers) + 1}, randFr);
+        const genesis = randHex(32);
+        const h = {...randomHeader(true, encodeExtra(), randHex), parentHash: genesis, number: BigNumber.from(1)};
+        const round = 1;
+
+        const message = committedSealMessage(headerHash(h), round);
+        let bitmap = BigNumber.from(0);
+        let sig;
+        for (const i of signers) {
+            const {signature} = bls254.signWithDST(SEAL_DST, message, secrets[i]);
+            sig = sig ? bls254.aggreagate(sig, signature) : signature;
+            bitmap = bitmap.or(BigNumber.from(1).shl(i));
+        }
+        const signature = ethers.utils.hexConcat(bls254.g1ToHex(sig));
+        h.extra = encodeExtra({aggregatedSeal: {bitmap, signature, round}});
+        vectors.aggregatedSeals.push({
+            validators: secrets.map((secret) => ({secret: fr(secret), pubkey: g2(bls254.g2Mul(secret, bls254.g2()))})),
+            genesis, rlp: encodeHeader(h), signers, bitmap: bitmap.toNumber(), signature,
+        });
+    }
+    return vectors;
+}
+
+function flag(name, fallback) {
+    const i = process.argv.indexOf(name);
+    return i === -1 ? fallback : process.argv[i + 1];
+}
+
+async function main() {
+    await bls254.init();
+    const seed = flag('--seed', 'solidity_bn256');
+    const count = Number(flag('--count', '8'));
+    const out = flag('--out', path.join(__dirname, '..', 'test', 'testdata', 'vectors.json'));
+
+    fs.writeFileSync(out, JSON.stringify(generate(seed, count), null, 2) + '\n');
+    console.log('vectors written to', out);
+}
+
+if (require.main === module) {
+    main().catch((error) => {
+        console.error(error);
+        process.exit(1);
+    });
+}
+
+module.exports = {generate, seededRandom};
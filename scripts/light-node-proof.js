@@ -0,0 +1,41 @@
+// Fetches the storage proof of a header hash stored by a LightNode deployment, for
+// LightNodeProof.verifyHeaderHash on another chain.
+//
+//   node scripts/light-node-proof.js <rpc-url> <light-node-address> <header-number> [block]
+//
+// Prints the state root of the block the proof is taken at, with the account and storage
+// proofs. The consumer must trust that state root through its own view of this chain.
+const {ethers} = require('ethers');
+
+const ABI = ['function headerHashSlot(uint number) view returns (bytes32)'];
+
+async function main() {
+    const [url, address, number, block = 'latest'] = process.argv.slice(2);
+    if (!url || !address || number === undefined) {
+        console.error('usage: node scripts/light-node-proof.js <rpc-url> <light-node-address> <header-number> [block]');
+        process.exit(2);
+    }
+
+    const provider = new ethers.providers.JsonRpcProvider(url);
+    const blockTag = block === 'latest' ? block : ethers.utils.hexValue(Number(block));
+    const header = await provider.send('eth_getBlockByNumber', [blockTag, false]);
+    const slot = await new ethers.Contract(address, ABI, provider).headerHashSlot(number, {blockTag: header.number});
+    const res = await provider.send('eth_getProof', [address, [slot], header.number]);
+
+    console.log(JSON.stringify({
+        block: ethers.BigNumber.from(header.number).toNumber(),
+        stateRoot: header.stateRoot,
+        lightNode: address,
+        number: Number(number),
+        headerHash: ethers.utils.hexZeroPad(res.storageProof[0].value, 32),
+        accountProof: res.accountProof,
+        storageProof: res.storageProof[0].proof,
+    }, null, 2));
+}
+
+main()
+  .then(() => process.exit(0))
+  .catch((error) => {
+    console.error(error);
+    process.exit(1);
+  });
@@ -36,7 +36,7 @@ var __generator = (this && this.__generator) || function (thisArg, body) {
     }
 };
 exports.__esModule = true;
-exports.bigToHex = exports.randHex = exports.randG2 = exports.randG1 = exports.randFr = exports.newG2 = exports.newG1 = exports.compressSignature = exports.compressPubkey = exports.aggreagate = exports.verify = exports.sign = exports.newKeyPair = exports.g2ToHex = exports.g2ToBN = exports.g2ToCompressed = exports.g1ToHex = exports.g1ToBN = exports.g1ToCompressed = exports.signOfG2 = exports.signOfG1 = exports.g2Mul = exports.g1Mul = exports.g2 = exports.g1 = exports.mclToHex = exports.hashToG1 = exports.init = exports.ORDER = exports.PRIME = void 0;
+exports.bigToHex = exports.randHex = exports.randG2 = exports.randG1 = exports.randFr = exports.newG2 = exports.newG1 = exports.marshalPubkey = exports.marshalSignature = exports.compressSignature = exports.compressPubkey = exports.aggreagate = exports.verify = exports.signWithDST = exports.sign = exports.newKeyPair = exports.g2ToHex = exports.g2ToBN = exports.g2ToCompressed = exports.g1ToHex = exports.g1ToBN = exports.g1ToCompressed = exports.signOfG2 = exports.signOfG1 = exports.g2Mul = exports.g1Mul = exports.g2 = exports.g1 = exports.mclToHex = exports.hashToG1TryAndIncrement = exports.hashToG1 = exports.domainMessage = exports.init = exports.ORDER = exports.PRIME = void 0;
 var ethers_1 = require("ethers");
 var mcl = require('mcl-wasm');
 exports.PRIME = ethers_1.BigNumber.from('0x30644e72e131a029b85045b68181585d97816a916871ca8d3c208c16d87cfd47');
@@ -68,6 +68,47 @@ function hashToG1(msg) {
     return p;
 }
 exports.hashToG1 = hashToG1;
+// same as BGLS.domainMessage: len(dst) || dst || msg, dst is a utf8 tag
+function domainMessage(dst, msg) {
+    var tag = ethers_1.ethers.utils.toUtf8Bytes(dst);
+    if (tag.length == 0 || tag.length > 255) {
+        throw new Error('bad domain separation tag');
+    }
+    return ethers_1.ethers.utils.hexConcat([ethers_1.ethers.utils.hexlify(tag.length), tag, msg]);
+}
+exports.domainMessage = domainMessage;
+function modPow(base, exponent, modulus) {
+    var result = ethers_1.BigNumber.from(1);
+    base = base.mod(modulus);
+    while (!exponent.isZero()) {
+        if (exponent.and(1).eq(1))
+            result = result.mul(base).mod(modulus);
+        base = base.mul(base).mod(modulus);
+        exponent = exponent.shr(1);
+    }
+    return result;
+}
+// same as BGLS.hashToG1TryAndIncrement
+function hashToG1TryAndIncrement(msg) {
+    if (!ethers_1.ethers.utils.isHexString(msg)) {
+        throw new Error('message is expected to be hex string');
+    }
+    for (var counter = 0; counter < 256; counter++) {
+        var hash = ethers_1.BigNumber.from(ethers_1.ethers.utils.solidityKeccak256(['bytes', 'uint256'], [msg, counter]));
+        var x = hash.mod(exports.PRIME);
+        var px = x.mul(x).mod(exports.PRIME).mul(x).add(3).mod(exports.PRIME);
+        if (modPow(px, exports.PRIME.sub(1).div(2), exports.PRIME).eq(1)) {
+            var y = modPow(px, exports.PRIME.add(1).div(4), exports.PRIME);
+            if (!hash.shr(255).eq(y.and(1)))
+                y = exports.PRIME.sub(y);
+            var p = new mcl.G1();
+            p.setStr('1 ' + x.toHexString() + ' ' + y.toHexString(), 16);
+            return p;
+        }
+    }
+    throw new Error('hash to G1 failed');
+}
+exports.hashToG1TryAndIncrement = hashToG1TryAndIncrement;
 function mclToHex(p, prefix) {
     if (prefix === void 0) { prefix = true; }
     var arr = p.serialize();
@@ -104,13 +145,14 @@ function signOfG1(p) {
     return y.and(ONE).eq(ONE);
 }
 exports.signOfG1 = signOfG1;
+// parity of yr, or of yi when yr is zero, same as BN256G2.compress
 function signOfG2(p) {
     p.normalize();
     var y = mclToHex(p.getY(), false);
     var ONE = ethers_1.BigNumber.from(1);
-    return ethers_1.BigNumber.from('0x' + y.slice(64))
-        .and(ONE)
-        .eq(ONE);
+    var yr = ethers_1.BigNumber.from('0x' + y.slice(64));
+    var sign = yr.isZero() ? ethers_1.BigNumber.from('0x' + y.slice(0, 64)) : yr;
+    return sign.and(ONE).eq(ONE);
 }
 exports.signOfG2 = signOfG2;
 function g1ToCompressed(p) {
@@ -185,6 +227,14 @@ function sign(message, secret) {
     return { signature: signature, M: M };
 }
 exports.sign = sign;
+// same as BGLS.checkSignatureWithDST expects, dst is a utf8 tag
+function signWithDST(dst, message, secret) {
+    var M = hashToG1TryAndIncrement(domainMessage(dst, message));
+    var signature = mcl.mul(M, secret);
+    signature.normalize();
+    return { signature: signature, M: M };
+}
+exports.signWithDST = signWithDST;
 function verify(message, pubkey, signature) {
     var M = hashToG1(message);
     var e1 = mcl.pairing(M, pubkey);
@@ -206,6 +256,16 @@ function compressSignature(p) {
     return g1ToCompressed(p);
 }
 exports.compressSignature = compressSignature;
+// the encodings of helper/bls in atlas, see BLSCodec: x || y, and xi || xr || yi || yr || flag
+function marshalSignature(p) {
+    return ethers_1.ethers.utils.hexConcat(g1ToHex(p));
+}
+exports.marshalSignature = marshalSignature;
+function marshalPubkey(p) {
+    var hex = g2ToHex(p);
+    return ethers_1.ethers.utils.hexConcat([hex[1], hex[0], hex[3], hex[2], '0x00']);
+}
+exports.marshalPubkey = marshalPubkey;
 function newG1() {
     return new mcl.G1();
 }
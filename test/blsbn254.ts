@@ -34,6 +34,48 @@ export function hashToG1(msg: string) {
     return p;
 }
 
+// same as BGLS.domainMessage: len(dst) || dst || msg, dst is a utf8 tag
+export function domainMessage(dst: string, msg: string) {
+    const tag = ethers.utils.toUtf8Bytes(dst);
+    if (tag.length == 0 || tag.length > 255) {
+        throw new Error('bad domain separation tag');
+    }
+    return ethers.utils.hexConcat([ethers.utils.hexlify(tag.length), tag, msg]);
+}
+
+function modPow(base: BigNumber, exponent: BigNumber, modulus: BigNumber): BigNumber {
+    let result = BigNumber.from(1);
+    base = base.mod(modulus);
+    while (!exponent.isZero()) {
+        if (exponent.and(1).eq(1)) result = result.mul(base).mod(modulus);
+        base = base.mul(base).mod(modulus);
+        exponent = exponent.shr(1);
+    }
+    return result;
+}
+
+// same as BGLS.hashToG1TryAndIncrement
+export function hashToG1TryAndIncrement(msg: string) {
+    if (!ethers.utils.isHexString(msg)) {
+        throw new Error('message is expected to be hex string');
+    }
+
+    for (let counter = 0; counter < 256; counter++) {
+        const hash = BigNumber.from(ethers.utils.solidityKeccak256(['bytes', 'uint256'], [msg, counter]));
+        const x = hash.mod(PRIME);
+        const px = x.mul(x).mod(PRIME).mul(x).add(3).mod(PRIME);
+        if (modPow(px, PRIME.sub(1).div(2), PRIME).eq(1)) {
+            let y = modPow(px, PRIME.add(1).div(4), PRIME);
+            if (!hash.shr(255).eq(y.and(1))) y = PRIME.sub(y);
+
+            const p = new mcl.G1();
+            p.setStr('1 ' + x.toHexString() + ' ' + y.toHexString(), 16);
+            return p;
+        }
+    }
+    throw new Error('hash to G1 failed');
+}
+
 export function mclToHex(p: mclFP | mclFR, prefix: boolean = true) {
     const arr = p.serialize();
     let s = '';
@@ -71,13 +113,14 @@ export function signOfG1(p: mclG1): boolean {
     return y.and(ONE).eq(ONE);
 }
 
+// parity of yr, or of yi when yr is zero, same as BN256G2.compress
 export function signOfG2(p: mclG2): boolean {
     p.normalize();
     const y = mclToHex(p.getY(), false);
     const ONE = BigNumber.from(1);
-    return BigNumber.from('0x' + y.slice(64))
-        .and(ONE)
-        .eq(ONE);
+    const yr = BigNumber.from('0x' + y.slice(64));
+    const sign = yr.isZero() ? BigNumber.from('0x' + y.slice(0, 64)) : yr;
+    return sign.and(ONE).eq(ONE);
 }
 
 export function g1ToCompressed(p: mclG1) {
@@ -150,6 +193,14 @@ export function sign(message: string, secret: mclFR) {
     return {signature, M};
 }
 
+// same as BGLS.checkSignatureWithDST expects, dst is a utf8 tag
+export function signWithDST(dst: string, message: string, secret: mclFR) {
+    const M = hashToG1TryAndIncrement(domainMessage(dst, message));
+    const signature = mcl.mul(M, secret);
+    signature.normalize();
+    return {signature, M};
+}
+
 export function verify(message: string, pubkey: mclG2, signature: mclG1) {
     const M = hashToG1(message);
 
@@ -173,6 +224,16 @@ export function compressSignature(p: mclG1) {
     return g1ToCompressed(p);
 }
 
+// the encodings of helper/bls in atlas, see BLSCodec: x || y, and xi || xr || yi || yr || flag
+export function marshalSignature(p: mclG1) {
+    return ethers.utils.hexConcat(g1ToHex(p));
+}
+
+export function marshalPubkey(p: mclG2) {
+    const hex = g2ToHex(p);
+    return ethers.utils.hexConcat([hex[1], hex[0], hex[3], hex[2], '0x00']);
+}
+
 export function newG1() {
     return new mcl.G1();
 }
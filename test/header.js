@@ -0,0 +1,224 @@
+// atlas header helpers for the js tests, mirroring HeaderCodec and IstanbulExtra
+const {ethers} = require('ethers');
+const {BigNumber} = ethers;
+
+const RLP = ethers.utils.RLP;
+const VANITY = ethers.utils.hexZeroPad('0x', 32);
+const MSG_COMMIT = 2;
+// LightNode.SEAL_DST, the tag committed seals are signed under
+const SEAL_DST = 'MAP_BLS_SIG_V1';
+
+const randHex = (n) => ethers.utils.hexlify(ethers.utils.randomBytes(n));
+const rlpUint = (v) => BigNumber.from(v).isZero() ? '0x' : ethers.utils.hexlify(BigNumber.from(v));
+
+// HeaderCodec.Header from an eth_getBlockByNumber result
+function headerFromJson(h) {
+    return {
+        parentHash: h.parentHash,
+        coinbase: h.miner,
+        root: h.stateRoot,
+        txHash: h.transactionsRoot,
+        receiptHash: h.receiptsRoot,
+        bloom: h.logsBloom,
+        number: BigNumber.from(h.number),
+        gasLimit: BigNumber.from(h.gasLimit),
+        gasUsed: BigNumber.from(h.gasUsed),
+        time: BigNumber.from(h.timestamp),
+        extra: h.extraData,
+        mixDigest: h.mixHash,
+        nonce: h.nonce,
+        hasBaseFee: h.baseFeePerGas !== undefined,
+        baseFee: BigNumber.from(h.baseFeePerGas || 0),
+        hasBlobGas: h.blobGasUsed !== undefined,
+        blobGasUsed: BigNumber.from(h.blobGasUsed || 0),
+        excessBlobGas: BigNumber.from(h.excessBlobGas || 0),
+        hasParentBeaconRoot: h.parentBeaconBlockRoot !== undefined,
+        parentBeaconRoot: h.parentBeaconBlockRoot || ethers.constants.HashZero,
+    };
+}
+
+// rlp of the header the same way rlp.EncodeToBytes does it in go
+function encodeHeader(h, extra = h.extra) {
+    const fields = [
+        h.parentHash, h.coinbase, h.root, h.txHash, h.receiptHash, h.bloom,
+        rlpUint(h.number), rlpUint(h.gasLimit), rlpUint(h.gasUsed), rlpUint(h.time),
+        extra, h.mixDigest, h.nonce,
+    ];
+    // optional fields up to the last one present
+    const optional = [[rlpUint(h.baseFee)], [rlpUint(h.blobGasUsed), rlpUint(h.excessBlobGas)], [h.parentBeaconRoot]];
+    const present = h.hasParentBeaconRoot ? 3 : h.hasBlobGas ? 2 : h.hasBaseFee ? 1 : 0;
+    optional.slice(0, present).forEach(f => fields.push(...f));
+    return RLP.encode(fields);
+}
+
+// CompactHeader encoding, as Header.MarshalCompact writes it
+function encodeCompactHeader(h) {
+    const u = (v, n) => ethers.utils.hexZeroPad(BigNumber.from(v).toHexString(), n);
+    const emptyBloom = BigNumber.from(h.bloom).isZero();
+    const flags = (h.hasBaseFee ? 1 : 0) | (h.hasBlobGas ? 2 : 0) | (h.hasParentBeaconRoot ? 4 : 0) | (emptyBloom ? 8 : 0);
+    const fields = [
+        u(flags, 1), h.parentHash, h.coinbase, h.root, h.txHash, h.receiptHash, emptyBloom ? '0x' : h.bloom,
+        u(h.number, 8), u(h.gasLimit, 8), u(h.gasUsed, 8), u(h.time, 8), h.mixDigest, h.nonce,
+    ];
+    if (h.hasBaseFee) fields.push(u(h.baseFee, 32));
+    if (h.hasBlobGas) fields.push(u(h.blobGasUsed, 8), u(h.excessBlobGas, 8));
+    if (h.hasParentBeaconRoot) fields.push(h.parentBeaconRoot);
+    fields.push(h.extra);
+    return ethers.utils.hexConcat(fields);
+}
+
+const isBytes = (v, n) => typeof v === 'string' && (n === undefined || ethers.utils.hexDataLength(v) === n);
+// an integer as go decodes it: no leading zeros and at most n bytes
+const isUint = (v, n = 32) => isBytes(v) && ethers.utils.hexDataLength(v) <= n && !v.startsWith('0x00');
+
+// rlp that ethers decodes and encodes back to the same bytes, the only rlp go and the contracts take
+function canonical(hex) {
+    try {
+        return RLP.encode(RLP.decode(hex)) === hex;
+    } catch (e) {
+        return false;
+    }
+}
+
+// IstanbulExtra.isExtra: vanity || rlp of the 7 fields of the atlas layout
+function isExtra(extra) {
+    if (ethers.utils.hexDataLength(extra) <= 32) return false;
+    const rlp = ethers.utils.hexDataSlice(extra, 32);
+    if (!canonical(rlp)) return false;
+
+    const f = RLP.decode(rlp);
+    const isList = (v, isItem) => Array.isArray(v) && v.every(isItem);
+    const isSeal = (v) => Array.isArray(v) && v.length === 3 && isUint(v[0]) && isBytes(v[1]) && isUint(v[2]);
+    return Array.isArray(f) && f.length === 7 &&
+        isList(f[0], (v) => isBytes(v, 20)) && isList(f[1], (v) => isBytes(v)) && isList(f[2], (v) => isBytes(v)) &&
+        isUint(f[3]) && isBytes(f[4]) && isSeal(f[5]) && isSeal(f[6]);
+}
+
+function filterExtra(extra, keepSeal) {
+    const fields = RLP.decode(ethers.utils.hexDataSlice(extra, 32));
+    if (!keepSeal) fields[4] = '0x';
+    fields[5] = ['0x', '0x', '0x'];
+    return ethers.utils.hexConcat([ethers.utils.hexDataSlice(extra, 0, 32), RLP.encode(fields)]);
+}
+
+// Header.Hash()
+function headerHash(h) {
+    if (!isExtra(h.extra)) return ethers.utils.keccak256(encodeHeader(h));
+    return ethers.utils.keccak256(encodeHeader(h, filterExtra(h.extra, true)));
+}
+
+// sigHash in atlas, a header without istanbul extra filters to nil and encodes as an empty list
+function sigHash(h) {
+    if (!isExtra(h.extra)) return ethers.utils.keccak256(RLP.encode([]));
+    return ethers.utils.keccak256(encodeHeader(h, filterExtra(h.extra, false)));
+}
+
+// rand draws the random bytes, a seeded one makes the header reproducible
+function randomHeader(hasBaseFee, extra, rand = randHex) {
+    return {
+        parentHash: rand(32),
+        coinbase: ethers.utils.getAddress(rand(20)),
+        root: rand(32),
+        txHash: rand(32),
+        receiptHash: rand(32),
+        bloom: rand(256),
+        number: BigNumber.from(rand(4)),
+        gasLimit: BigNumber.from(rand(4)),
+        gasUsed: BigNumber.from(rand(2)),
+        time: BigNumber.from(rand(4)),
+        extra: extra,
+        mixDigest: rand(32),
+        nonce: rand(8),
+        hasBaseFee: hasBaseFee,
+        baseFee: hasBaseFee ? BigNumber.from(rand(6)) : BigNumber.from(0),
+        hasBlobGas: false,
+        blobGasUsed: BigNumber.from(0),
+        excessBlobGas: BigNumber.from(0),
+        hasParentBeaconRoot: false,
+        parentBeaconRoot: ethers.constants.HashZero,
+    };
+}
+
+const emptySeal = {bitmap: 0, signature: '0x', round: 0};
+const encodeSeal = (s) => [rlpUint(s.bitmap), s.signature, rlpUint(s.round)];
+
+// vanity || rlp(IstanbulExtra), every field is optional
+function encodeExtra(ist = {}) {
+    return ethers.utils.hexConcat([VANITY, RLP.encode([
+        ist.addedValidators || [],
+        ist.addedPubKeys || [],
+        ist.addedG1PubKeys || [],
+        rlpUint(ist.removedValidators || 0),
+        ist.seal || '0x',
+        encodeSeal(ist.aggregatedSeal || emptySeal),
+        encodeSeal(ist.parentAggregatedSeal || emptySeal),
+    ])]);
+}
+
+// PrepareCommittedSeal in atlas: hash || round.Bytes() || MsgCommit
+function committedSealMessage(hash, round) {
+    const r = BigNumber.from(round).isZero() ? '0x' : ethers.utils.hexlify(BigNumber.from(round));
+    return ethers.utils.hexConcat([hash, r, ethers.utils.hexlify(MSG_COMMIT)]);
+}
+
+// rlp of the go-ethereum header, EthHeaderCodec
+function encodeEthHeader(h) {
+    const fields = [
+        h.parentHash, h.uncleHash, h.coinbase, h.root, h.txHash, h.receiptHash, h.bloom,
+        rlpUint(h.difficulty), rlpUint(h.number), rlpUint(h.gasLimit), rlpUint(h.gasUsed), rlpUint(h.time),
+        h.extra, h.mixDigest, h.nonce,
+    ];
+    const optional = [
+        [rlpUint(h.baseFee)], [h.withdrawalsHash], [rlpUint(h.blobGasUsed), rlpUint(h.excessBlobGas), h.parentBeaconRoot], [h.requestsHash],
+    ];
+    const present = h.hasRequestsHash ? 4 : h.hasBlobGas ? 3 : h.hasWithdrawalsHash ? 2 : h.hasBaseFee ? 1 : 0;
+    optional.slice(0, present).forEach(f => fields.push(...f));
+    return RLP.encode(fields);
+}
+
+// a post-merge header of the given fork, 0 is london up to 3 for prague
+function randomEthHeader(fork) {
+    return {
+        parentHash: randHex(32),
+        uncleHash: ethers.utils.keccak256(RLP.encode([])),
+        coinbase: ethers.utils.getAddress(randHex(20)),
+        root: randHex(32),
+        txHash: randHex(32),
+        receiptHash: randHex(32),
+        bloom: randHex(256),
+        difficulty: BigNumber.from(0),
+        number: BigNumber.from(randHex(3)),
+        gasLimit: BigNumber.from(randHex(4)),
+        gasUsed: BigNumber.from(randHex(3)),
+        time: BigNumber.from(randHex(4)),
+        extra: randHex(fork * 5),
+        mixDigest: randHex(32),
+        nonce: '0x0000000000000000',
+        hasBaseFee: true,
+        baseFee: BigNumber.from(randHex(5)),
+        hasWithdrawalsHash: fork >= 1,
+        withdrawalsHash: fork >= 1 ? randHex(32) : ethers.constants.HashZero,
+        hasBlobGas: fork >= 2,
+        blobGasUsed: fork >= 2 ? BigNumber.from(0x40000) : BigNumber.from(0),
+        excessBlobGas: fork >= 2 ? BigNumber.from(randHex(3)) : BigNumber.from(0),
+        parentBeaconRoot: fork >= 2 ? randHex(32) : ethers.constants.HashZero,
+        hasRequestsHash: fork >= 3,
+        requestsHash: fork >= 3 ? randHex(32) : ethers.constants.HashZero,
+    };
+}
+
+const NEVER = ethers.constants.MaxUint256;
+
+// ForkSchedule.ChainConfig of a chain with base fees from genesis, no blob gas and the atlas extra,
+// keeping every header unless a checkpoint interval is given
+function chainConfig(epochSize, forks = {}, checkpointInterval = 1) {
+    return {
+        epochSize,
+        checkpointInterval,
+        forks: {baseFeeBlock: 0, blobGasBlock: NEVER, layoutBlocks: [0], layouts: [{vanity: 32, hasG1PubKeys: true}], ...forks},
+    };
+}
+
+module.exports = {
+    NEVER, SEAL_DST, chainConfig, canonical, isBytes, isUint, isExtra, encodeEthHeader, randomEthHeader, rlpUint, headerFromJson, encodeHeader, encodeCompactHeader, filterExtra, headerHash, sigHash, randomHeader, encodeExtra, committedSealMessage,
+};
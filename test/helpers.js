@@ -0,0 +1,42 @@
+// helpers shared by the js tests
+const hre = require('hardhat');
+const {assert} = require('chai');
+const {BigNumber} = require("ethers");
+const bls254 = require('./blsbn254');
+
+// BGLS.G1 of an mcl G1 point
+function convertG1(mclG1) {
+    const hex = bls254.g1ToHex(mclG1);
+    return {x: BigNumber.from(hex[0]), y: BigNumber.from(hex[1])};
+}
+
+// BGLS.G2 of an mcl G2 point
+function convertG2(mclG2) {
+    const hex = bls254.g2ToHex(mclG2);
+    return {
+        xr: BigNumber.from(hex[0]),
+        xi: BigNumber.from(hex[1]),
+        yr: BigNumber.from(hex[2]),
+        yi: BigNumber.from(hex[3]),
+    };
+}
+
+async function assertRevert(promise, reason) {
+    try {
+        await promise;
+    } catch (e) {
+        assert(e.message.includes(reason), e.message);
+        return;
+    }
+    assert.fail('expected revert with ' + reason);
+}
+
+// LightNode factory linked with a freshly deployed LightNodeCodec
+async function lightNodeFactory() {
+    const LightNodeCodec = await hre.ethers.getContractFactory('LightNodeCodec');
+    const codec = await LightNodeCodec.deploy();
+    await codec.deployed();
+    return hre.ethers.getContractFactory('LightNode', {libraries: {LightNodeCodec: codec.address}});
+}
+
+module.exports = {assertRevert, convertG1, convertG2, lightNodeFactory};
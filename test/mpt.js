@@ -0,0 +1,116 @@
+// minimal merkle patricia trie for building proofs in tests, same layout as trie.Trie in go-ethereum
+const {ethers} = require('ethers');
+
+const RLP = ethers.utils.RLP;
+
+function toNibbles(hex) {
+    const bytes = ethers.utils.arrayify(hex);
+    const nibbles = [];
+    bytes.forEach(b => nibbles.push(b >> 4, b & 0x0f));
+    return nibbles;
+}
+
+// hex prefix encoding of a path
+function compact(nibbles, isLeaf) {
+    const flag = isLeaf ? 2 : 0;
+    const n = nibbles.length % 2 === 1 ? [flag + 1, ...nibbles] : [flag, 0, ...nibbles];
+    const bytes = [];
+    for (let i = 0; i < n.length; i += 2) bytes.push(n[i] * 16 + n[i + 1]);
+    return ethers.utils.hexlify(bytes);
+}
+
+class Trie {
+    // entries: [[key, value]] as hex strings
+    constructor(entries) {
+        this.nodes = {}; // hash => node
+        const sorted = entries.map(([k, v]) => ({nibbles: toNibbles(k), value: v}));
+        this.root = this.build(sorted, 0);
+    }
+
+    rootHash() {
+        return ethers.utils.keccak256(RLP.encode(this.root));
+    }
+
+    // nodes shorter than 32 bytes are embedded, everything else is referenced by hash
+    ref(node) {
+        const encoded = RLP.encode(node);
+        if (ethers.utils.hexDataLength(encoded) < 32) return node;
+
+        const hash = ethers.utils.keccak256(encoded);
+        this.nodes[hash] = node;
+        return hash;
+    }
+
+    build(entries, depth) {
+        if (entries.length === 0) return '0x';
+        if (entries.length === 1) {
+            return [compact(entries[0].nibbles.slice(depth), true), entries[0].value];
+        }
+
+        let shared = 0;
+        while (entries.every(e => e.nibbles.length > depth + shared && e.nibbles[depth + shared] === entries[0].nibbles[depth + shared])) {
+            shared++;
+        }
+        if (shared > 0) {
+            const path = entries[0].nibbles.slice(depth, depth + shared);
+            return [compact(path, false), this.ref(this.build(entries, depth + shared))];
+        }
+
+        const branch = Array(17).fill('0x');
+        entries.filter(e => e.nibbles.length === depth).forEach(e => branch[16] = e.value);
+        for (let i = 0; i < 16; i++) {
+            const group = entries.filter(e => e.nibbles.length > depth && e.nibbles[depth] === i);
+            if (group.length > 0) branch[i] = this.ref(this.build(group, depth + 1));
+        }
+        return branch;
+    }
+
+    // encoded nodes from the root down to key, also a proof of absence when key is missing
+    prove(key) {
+        const nibbles = toNibbles(key);
+        const proof = [RLP.encode(this.root)];
+        let node = this.root;
+        let depth = 0;
+
+        for (;;) {
+            let child;
+            if (node.length === 17) {
+                if (depth === nibbles.length) return proof;
+                child = node[nibbles[depth++]];
+            } else {
+                const path = toNibbles(node[0]);
+                const rest = path[0] % 2 === 1 ? path.slice(1) : path.slice(2);
+                if (nibbles.slice(depth, depth + rest.length).join() !== rest.join()) return proof;
+                depth += rest.length;
+                if (path[0] >= 2) return proof;
+                child = node[1];
+            }
+
+            if (child === '0x') return proof;
+            if (Array.isArray(child)) {
+                node = child;
+            } else {
+                node = this.nodes[child];
+                proof.push(RLP.encode(node));
+            }
+        }
+    }
+}
+
+// key of the i-th transaction or receipt, rlp.AppendUint64(nil, i)
+function indexKey(i) {
+    return RLP.encode(i === 0 ? '0x' : ethers.utils.hexlify(i));
+}
+
+// MPTVerify.ProofBundle of proofs from the same trie, every distinct node is listed once
+function packProofs(proofs) {
+    const nodes = [];
+    const index = {};
+    const refs = proofs.map(proof => proof.map(node => {
+        if (index[node] === undefined) index[node] = nodes.push(node) - 1;
+        return index[node];
+    }));
+    return {nodes, proofs: refs};
+}
+
+module.exports = {Trie, indexKey, packProofs};
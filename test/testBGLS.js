@@ -1,26 +1,15 @@
 const hre = require('hardhat');
 const {assert} = require('chai');
 const {ethers} = require('hardhat');
+const mcl = require('mcl-wasm');
 const bls254 = require('./blsbn254');
 const {BigNumber} = require("ethers");
+const {assertRevert, convertG1, convertG2} = require('./helpers');
 
 const formatG1 = (p) => p.x.toHexString() + ',' + p.y.toHexString();
 const equalG1 = (p, q) => p.x.eq(q.x) && p.y.eq(q.y);
 
-function convertG1(mclG1) {
-    const hex = bls254.g1ToHex(mclG1);
-    return {x: BigNumber.from(hex[0]), y: BigNumber.from(hex[1])};
-}
-
-function convertG2(mclG2) {
-    const hex = bls254.g2ToHex(mclG2);
-    return {
-        xr: BigNumber.from(hex[0]),
-        xi: BigNumber.from(hex[1]),
-        yr: BigNumber.from(hex[2]),
-        yi: BigNumber.from(hex[3]),
-    };
-}
+const utf8 = (s) => ethers.utils.hexlify(ethers.utils.toUtf8Bytes(s));
 
 describe('BGLS', function () {
     let bgls;
@@ -42,6 +31,24 @@ describe('BGLS', function () {
         assert(equalG1(P1, Q1));
     });
 
+    it("should hash to same G1 point with try-and-increment", async () => {
+        for (const msg of [message, '0x', '0x00', bls254.randHex(32), bls254.randHex(100)]) {
+            const P1 = await bgls.callStatic.hashToG1TryAndIncrement(msg);
+            const Q1 = convertG1(bls254.hashToG1TryAndIncrement(msg));
+
+            assert(equalG1(P1, Q1), formatG1(P1) + ' != ' + formatG1(Q1));
+        }
+    });
+
+    it("should verify signature over try-and-increment hash", async () => {
+        const keypair = bls254.newKeyPair();
+        const M = bls254.hashToG1TryAndIncrement(message);
+        const sig = bls254.g1Mul(keypair.secret, M);
+
+        res = await bgls.callStatic.pairingCheck(convertG1(sig), convertG2(bls254.g2()), convertG1(M), convertG2(keypair.pubkey));
+        assert(res === true);
+    });
+
     it("should verify valid signature", async () => {
         const keypair = bls254.newKeyPair(); // pubKey: G2, secret: Fr (BigNumber)
         res = bls254.sign(message, keypair.secret); // signature: G1, M: G1
@@ -112,5 +119,49 @@ describe('BGLS', function () {
         res = await bgls.callStatic.checkSignature(message, convertG1(aggSig), convertG2(key2.pubkey));
         assert(res === false);
     });
-});
 
+    it("should separate signatures by domain", async () => {
+        const keypair = bls254.newKeyPair();
+        const pkG2 = convertG2(keypair.pubkey);
+        const seal = bls254.signWithDST('MAP_BLS_SIG_V1', message, keypair.secret);
+        const sigG1 = convertG1(seal.signature);
+
+        const P1 = await bgls.callStatic.hashToG1WithDST(utf8('MAP_BLS_SIG_V1'), message);
+        assert(equalG1(P1, convertG1(seal.M)));
+
+        assert(await bgls.callStatic.checkSignatureWithDST(utf8('MAP_BLS_SIG_V1'), message, sigG1, pkG2));
+        assert.isFalse(await bgls.callStatic.checkSignatureWithDST(utf8('MAP_BLS_EPOCH_V1'), message, sigG1, pkG2));
+        assert.isFalse(await bgls.callStatic.checkSignature(message, sigG1, pkG2));
+
+        await assertRevert(bgls.callStatic.checkSignatureWithDST('0x', message, sigG1, pkG2), 'bad domain separation tag');
+        await assertRevert(bgls.callStatic.hashToG1WithDST(bls254.randHex(256), message), 'bad domain separation tag');
+    });
+
+    it("should not let a signature be moved to another domain", async () => {
+        const keypair = bls254.newKeyPair();
+        const pkG2 = convertG2(keypair.pubkey);
+        const tagged = (dst) => bls254.domainMessage(dst, message);
+
+        // hashToG1 is keccak(m) * g1, so sig_B = (h_B / h_A) * sig_A for h = keccak(m) mod r
+        const scalar = (m) => {
+            const fr = new mcl.Fr();
+            fr.setStr(BigNumber.from(ethers.utils.keccak256(m)).mod(bls254.ORDER).toString());
+            return fr;
+        };
+        const ratio = mcl.div(scalar(tagged('MAP_BLS_EPOCH_V1')), scalar(tagged('MAP_BLS_SIG_V1')));
+        const move = (sig) => {
+            const moved = mcl.mul(sig, ratio);
+            moved.normalize();
+            return convertG1(moved);
+        };
+
+        // over hashToG1 the moved signature checks
+        const plain = bls254.sign(tagged('MAP_BLS_SIG_V1'), keypair.secret).signature;
+        assert(await bgls.callStatic.checkSignature(tagged('MAP_BLS_EPOCH_V1'), move(plain), pkG2));
+
+        // with a tag it does not
+        const seal = bls254.signWithDST('MAP_BLS_SIG_V1', message, keypair.secret).signature;
+        assert(await bgls.callStatic.checkSignatureWithDST(utf8('MAP_BLS_SIG_V1'), message, convertG1(seal), pkG2));
+        assert.isFalse(await bgls.callStatic.checkSignatureWithDST(utf8('MAP_BLS_EPOCH_V1'), message, move(seal), pkG2));
+    });
+});
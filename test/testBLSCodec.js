@@ -0,0 +1,62 @@
+const hre = require('hardhat');
+const {assert} = require('chai');
+const {ethers} = require('hardhat');
+const bls254 = require('./blsbn254');
+const {BigNumber} = require("ethers");
+const {assertRevert, convertG1, convertG2} = require('./helpers');
+
+const equalG1 = (p, q) => p.x.eq(q.x) && p.y.eq(q.y);
+const equalG2 = (p, q) => p.xr.eq(q.xr) && p.xi.eq(q.xi) && p.yr.eq(q.yr) && p.yi.eq(q.yi);
+
+describe('BLSCodec', function () {
+    let codec;
+
+    before(async () => {
+        await bls254.init();
+        const TestBLSCodec = await hre.ethers.getContractFactory('TestBLSCodec');
+        codec = await TestBLSCodec.deploy();
+        await codec.deployed();
+    });
+
+    it("should read and write atlas signatures", async () => {
+        for (let i = 0; i < 5; i++) {
+            const {secret} = bls254.newKeyPair();
+            const {signature} = bls254.sign(bls254.randHex(32), secret);
+            const data = bls254.marshalSignature(signature);
+
+            assert.equal(ethers.utils.hexDataLength(data), 64);
+            assert(equalG1(await codec.decodeG1(data), convertG1(signature)));
+            assert.equal(await codec.encodeG1(convertG1(signature)), data);
+        }
+    });
+
+    it("should read and write atlas public keys", async () => {
+        for (let i = 0; i < 5; i++) {
+            const {pubkey} = bls254.newKeyPair();
+            const data = bls254.marshalPubkey(pubkey);
+
+            assert.equal(ethers.utils.hexDataLength(data), 129);
+            assert(equalG2(await codec.decodeG2(data), convertG2(pubkey)));
+            assert.equal(await codec.encodeG2(convertG2(pubkey)), data);
+
+            // the flag byte is optional and zero
+            assert(equalG2(await codec.decodeG2(ethers.utils.hexDataSlice(data, 0, 128)), convertG2(pubkey)));
+            await assertRevert(codec.decodeG2(ethers.utils.hexConcat([ethers.utils.hexDataSlice(data, 0, 128), '0x01'])), 'bad G2 encoding');
+        }
+    });
+
+    it("should reject bad encodings", async () => {
+        const {pubkey, secret} = bls254.newKeyPair();
+        const sig = bls254.marshalSignature(bls254.sign(bls254.randHex(32), secret).signature);
+        const key = bls254.marshalPubkey(pubkey);
+
+        await assertRevert(codec.decodeG1(ethers.utils.hexDataSlice(sig, 1)), 'bad G1 encoding');
+        await assertRevert(codec.decodeG1(ethers.utils.hexConcat([sig, '0x00'])), 'bad G1 encoding');
+        const yPlusP = bls254.bigToHex(BigNumber.from(ethers.utils.hexDataSlice(sig, 32)).add(bls254.PRIME));
+        await assertRevert(codec.decodeG1(ethers.utils.hexConcat([ethers.utils.hexDataSlice(sig, 0, 32), yPlusP])), 'bad G1 encoding');
+
+        await assertRevert(codec.decodeG2(ethers.utils.hexDataSlice(key, 2)), 'bad G2 encoding');
+        const offCurve = ethers.utils.hexConcat([ethers.utils.hexDataSlice(key, 0, 96), bls254.bigToHex(BigNumber.from(ethers.utils.hexDataSlice(key, 96, 128)).add(1))]);
+        await assertRevert(codec.decodeG2(offCurve), 'bad G2 encoding');
+    });
+});
@@ -0,0 +1,162 @@
+const hre = require('hardhat');
+const {assert} = require('chai');
+const bls254 = require('./blsbn254');
+const {BigNumber} = require("ethers");
+const {assertRevert, convertG1, convertG2} = require('./helpers');
+
+const equalG2 = (p, q) => p.xr.eq(q.xr) && p.xi.eq(q.xi) && p.yr.eq(q.yr) && p.yi.eq(q.yi);
+
+// EpochSnarkData.Bitmap (a big integer, bit i = validator i) to the contract's byte layout
+function bitmapToBits(bitmap, n) {
+    const bytes = new Uint8Array((n + 7) >> 3);
+    for (let i = 0; i < n; i++) {
+        if (!bitmap.shr(i).and(1).isZero()) bytes[i >> 3] |= 1 << (i % 8);
+    }
+    return hre.ethers.utils.hexlify(bytes);
+}
+
+describe('BLSVerify', function () {
+    let verifier;
+    let signers;
+
+    const num = 10;
+    const message = '0x6162636566676869';
+
+    // aggregate signature and bitmap over the given signer indices
+    function aggregate(indices) {
+        let bitmap = BigNumber.from(0);
+        let aggSig;
+        let aggPk;
+        indices.forEach(i => {
+            const sig = bls254.sign(message, signers[i].secret).signature;
+            aggSig = aggSig ? bls254.aggreagate(aggSig, sig) : sig;
+            aggPk = aggPk ? bls254.aggreagate(aggPk, signers[i].pubkey) : signers[i].pubkey;
+            bitmap = bitmap.or(BigNumber.from(1).shl(i));
+        });
+        return {bits: bitmapToBits(bitmap, num), sig: convertG1(aggSig), aggPk: convertG2(aggPk)};
+    }
+
+    before(async () => {
+        await bls254.init();
+        signers = Array.from({length: num}, () => bls254.newKeyPair());
+
+        const BLSVerify = await hre.ethers.getContractFactory('BLSVerify');
+        verifier = await BLSVerify.deploy();
+        await verifier.deployed();
+    });
+
+    it("should aggregate selected pubkeys", async () => {
+        const agg = aggregate([0, 3, 8, 9]);
+        const pubkeys = signers.map(s => convertG2(s.pubkey));
+
+        assert(equalG2(await verifier.aggregatePubkeys(agg.bits, pubkeys), agg.aggPk));
+    });
+
+    it("should verify aggregated signature", async () => {
+        const pubkeys = signers.map(s => convertG2(s.pubkey));
+
+        for (const indices of [[0], [1, 2], [0, 3, 8, 9], [...Array(num).keys()]]) {
+            const agg = aggregate(indices);
+            assert(await verifier.callStatic.verifyAggregate(agg.sig, agg.bits, pubkeys, message));
+        }
+    });
+
+    it("should not verify with wrong bitmap or message", async () => {
+        const pubkeys = signers.map(s => convertG2(s.pubkey));
+        const agg = aggregate([0, 3, 8]);
+        const other = aggregate([0, 3, 9]);
+
+        assert.equal(await verifier.callStatic.verifyAggregate(agg.sig, other.bits, pubkeys, message), false);
+        assert.equal(await verifier.callStatic.verifyAggregate(agg.sig, agg.bits, pubkeys, '0x616263'), false);
+    });
+
+    it("should not verify empty bitmap", async () => {
+        const pubkeys = signers.map(s => convertG2(s.pubkey));
+        const agg = aggregate([0]);
+
+        assert.equal(await verifier.callStatic.verifyAggregate(agg.sig, bitmapToBits(BigNumber.from(0), num), pubkeys, message), false);
+    });
+
+    it("should reject bits past the validators", async () => {
+        const pubkeys = signers.map(s => convertG2(s.pubkey));
+        const validators = pubkeys.map(pubkey => ({pubkey, weight: 1}));
+        // bit 10 is in the last byte of 10 validators
+        const bits = bitmapToBits(BigNumber.from(1).shl(num), 16);
+
+        await assertRevert(verifier.aggregatePubkeys(bits, pubkeys), 'unused bits set');
+        await assertRevert(verifier.aggregateWeighted(bits, validators), 'unused bits set');
+    });
+
+    it("should reject more than 256 validators", async () => {
+        const pubkeys = Array(257).fill(convertG2(signers[0].pubkey));
+        const bits = bitmapToBits(BigNumber.from(1), 257);
+
+        await assertRevert(verifier.aggregatePubkeys(bits, pubkeys), 'too many validators');
+        await assertRevert(verifier.aggregateWeighted(bits, pubkeys.map(pubkey => ({pubkey, weight: 1}))), 'too many validators');
+    });
+
+    it("should reject selected pubkeys off the twist", async () => {
+        const pubkeys = signers.map(s => convertG2(s.pubkey));
+        pubkeys[3] = {...pubkeys[3], yr: pubkeys[3].yr.add(1).mod(bls254.PRIME)};
+
+        await assertRevert(verifier.aggregatePubkeys(aggregate([0, 3]).bits, pubkeys), 'invalid G2 point');
+        // keys outside the bitmap are not looked at
+        const agg = aggregate([0, 1]);
+        assert(await verifier.callStatic.verifyAggregate(agg.sig, agg.bits, pubkeys, message));
+    });
+
+    it("should reject selected pubkeys outside the subgroup", async () => {
+        const TestBN256G2 = await hre.ethers.getContractFactory('TestBN256G2');
+        const g2 = await TestBN256G2.deploy();
+        await g2.deployed();
+
+        // on the twist, but the cofactor makes it almost surely miss the order r subgroup
+        const pubkeys = signers.map(s => convertG2(s.pubkey));
+        for (let x = pubkeys[3].xr; ; x = x.add(1)) {
+            try {
+                pubkeys[3] = await g2.decompress(x, pubkeys[3].xi);
+                break;
+            } catch (e) {}
+        }
+
+        await assertRevert(verifier.aggregatePubkeys(aggregate([0, 3]).bits, pubkeys), 'invalid G2 point');
+        const validators = pubkeys.map(pubkey => ({pubkey, weight: 1}));
+        await assertRevert(verifier.aggregateWeighted(aggregate([0, 3]).bits, validators), 'invalid G2 point');
+    });
+
+    it("should sum signer and total weights", async () => {
+        const weights = [5, 1, 1, 1, 1, 1, 1, 1, 1, 2];
+        const validators = signers.map((s, i) => ({pubkey: convertG2(s.pubkey), weight: weights[i]}));
+        const agg = aggregate([0, 3, 9]);
+
+        const res = await verifier.aggregateWeighted(agg.bits, validators);
+        assert(equalG2(res.aggPk, agg.aggPk));
+        assert(res.signedWeight.eq(8));
+        assert(res.totalWeight.eq(15));
+    });
+
+    it("should verify weighted signatures above 2/3 of the weight", async () => {
+        // total 15, more than 10 is needed
+        const weights = [5, 1, 1, 1, 1, 1, 1, 1, 1, 2];
+        const validators = signers.map((s, i) => ({pubkey: convertG2(s.pubkey), weight: weights[i]}));
+
+        const heavy = aggregate([0, 1, 2, 3, 4, 9]); // 11
+        assert(await verifier.callStatic.verifyWeighted(heavy.sig, heavy.bits, validators, message));
+
+        const exact = aggregate([0, 1, 2, 3, 9]); // 10
+        assert.isFalse(await verifier.callStatic.verifyWeighted(exact.sig, exact.bits, validators, message));
+
+        const many = aggregate([1, 2, 3, 4, 5, 6, 7, 8]); // 8 of 10 signers, weight 8
+        assert.isFalse(await verifier.callStatic.verifyWeighted(many.sig, many.bits, validators, message));
+
+        assert.isFalse(await verifier.callStatic.verifyWeighted(heavy.sig, heavy.bits, validators, '0x616263'));
+    });
+
+    it("should reject weights over the cap", async () => {
+        const max = await verifier.MAX_WEIGHT();
+        const validators = signers.map(s => ({pubkey: convertG2(s.pubkey), weight: 1}));
+        validators[5].weight = max.add(1);
+
+        await assertRevert(verifier.aggregateWeighted(aggregate([0]).bits, validators), 'weight too large');
+    });
+});
@@ -0,0 +1,155 @@
+const hre = require('hardhat');
+const {assert} = require('chai');
+const bls254 = require('./blsbn254');
+const {BigNumber} = require("ethers");
+const {assertRevert, convertG1} = require('./helpers');
+
+function frToBN(fr) {
+    return BigNumber.from(bls254.mclToHex(fr));
+}
+
+describe('BN256G1', function () {
+    let g1;
+
+    before(async () => {
+        await bls254.init();
+        const TestBN256G1 = await hre.ethers.getContractFactory('TestBN256G1');
+        g1 = await TestBN256G1.deploy();
+        await g1.deployed();
+    });
+
+    it("should multiply the generator", async () => {
+        for (let i = 0; i < 3; i++) {
+            const k = bls254.randFr();
+            const res = await g1.mulGen(frToBN(k));
+            const expected = convertG1(bls254.g1Mul(k, bls254.g1()));
+            assert(res.x.eq(expected.x) && res.y.eq(expected.y));
+        }
+    });
+
+    it("should round trip compressed points", async () => {
+        for (let i = 0; i < 10; i++) {
+            const p = bls254.randG1();
+            const compressed = bls254.g1ToCompressed(p);
+
+            assert((await g1.compress(convertG1(p))).eq(compressed));
+
+            const res = await g1.decompress(compressed);
+            const expected = convertG1(p);
+            assert(res.x.eq(expected.x) && res.y.eq(expected.y));
+        }
+
+        const g = await g1.decompress(1);
+        assert(g.x.eq(1) && g.y.eq(2));
+    });
+
+    it("should round trip the point at infinity", async () => {
+        const c = await g1.compress({x: 0, y: 0});
+        assert(c.eq(BigNumber.from(1).shl(254)));
+
+        const res = await g1.decompress(c);
+        assert(res.x.isZero() && res.y.isZero());
+    });
+
+    it("should reject invalid compressed points", async () => {
+        await assertRevert(g1.decompress(bls254.PRIME), 'invalid compressed G1 point');
+        await assertRevert(g1.decompress(BigNumber.from(1).shl(254).add(1)), 'invalid compressed G1 point');
+
+        // x = 4: 4^3 + 3 = 67 is not a square mod p
+        await assertRevert(g1.decompress(4), 'invalid compressed G1 point');
+    });
+
+    it("should decompress without the modexp precompile", async () => {
+        for (let i = 0; i < 5; i++) {
+            const compressed = bls254.g1ToCompressed(bls254.randG1());
+            const res = await g1.decompressPure(compressed);
+            const expected = await g1.decompress(compressed);
+            assert(res.x.eq(expected.x) && res.y.eq(expected.y));
+        }
+
+        const g = await g1.decompressPure(1);
+        assert(g.x.eq(1) && g.y.eq(2));
+        const inf = await g1.decompressPure(BigNumber.from(1).shl(254));
+        assert(inf.x.isZero() && inf.y.isZero());
+
+        await assertRevert(g1.decompressPure(bls254.PRIME), 'invalid compressed G1 point');
+        await assertRevert(g1.decompressPure(BigNumber.from(1).shl(254).add(1)), 'invalid compressed G1 point');
+        await assertRevert(g1.decompressPure(4), 'invalid compressed G1 point');
+    });
+
+    it("should take square roots same as the modexp precompile", async () => {
+        const p = bls254.PRIME;
+        const edges = [0, 1, 2, 3, 4, 67, p.sub(1), p.sub(4), p, p.add(4), BigNumber.from(2).pow(256).sub(1)];
+        for (const a of [...edges, ...Array.from({length: 5}, () => BigNumber.from(bls254.randHex(32)))]) {
+            const [ok, r] = await g1.sqrtMod(a, p);
+            const reduced = BigNumber.from(a).mod(p);
+            const [expected] = await g1.fpSqrt(reduced);
+
+            assert.equal(ok, expected, `sqrt of ${a}`);
+            if (ok) assert(r.mul(r).mod(p).eq(reduced), `sqrt of ${a}`);
+        }
+    });
+
+    it("should take square roots modulo primes with a large 2-adic part", async () => {
+        // order - 1 = q * 2^28
+        const n = bls254.ORDER;
+        for (let i = 0; i < 5; i++) {
+            const k = BigNumber.from(bls254.randHex(32)).mod(n);
+            const a = k.mul(k).mod(n);
+            const [ok, r] = await g1.sqrtMod(a, n);
+            assert(ok && r.mul(r).mod(n).eq(a));
+        }
+        // 5 generates the multiplicative group of the scalar field
+        assert.isFalse((await g1.sqrtMod(5, n))[0]);
+        assert((await g1.sqrtMod(n.sub(1), n))[0]);
+    });
+
+    it("should check points are on the curve", async () => {
+        const p = convertG1(bls254.randG1());
+
+        assert(await g1.isOnCurveG1(p));
+        assert(await g1.isOnCurveG1({x: 1, y: 2}));
+        assert(await g1.isOnCurveG1({x: 0, y: 0}));
+        assert.isFalse(await g1.isOnCurveG1({x: 1, y: 3}));
+        assert.isFalse(await g1.isOnCurveG1({x: p.x, y: p.y.add(1)}));
+        assert.isFalse(await g1.isOnCurveG1({x: 1, y: bls254.PRIME.add(2)}));
+    });
+
+    it("should compute multi-scalar multiplications same as mcl", async () => {
+        for (const n of [1, 2, 7, 20]) {
+            const points = [];
+            const scalars = [];
+            let expected;
+            for (let i = 0; i < n; i++) {
+                const p = bls254.randG1();
+                const k = bls254.randFr();
+                const kp = bls254.g1Mul(k, p);
+                expected = expected ? bls254.aggreagate(expected, kp) : kp;
+                points.push(convertG1(p));
+                // scalars are not required to be reduced
+                scalars.push(i % 2 === 0 ? frToBN(k) : frToBN(k).add(bls254.ORDER));
+            }
+            expected.normalize();
+
+            const res = await g1.msm(points, scalars);
+            const e = convertG1(expected);
+            assert(res.x.eq(e.x) && res.y.eq(e.y));
+        }
+    });
+
+    it("should handle empty and zero multi-scalar multiplications", async () => {
+        const p = convertG1(bls254.randG1());
+
+        const empty = await g1.msm([], []);
+        assert(empty.x.isZero() && empty.y.isZero());
+
+        const zero = await g1.msm([p, {x: 0, y: 0}], [0, 5]);
+        assert(zero.x.isZero() && zero.y.isZero());
+
+        // p - p
+        const cancel = await g1.msm([p, {x: p.x, y: bls254.PRIME.sub(p.y)}], [3, 3]);
+        assert(cancel.x.isZero() && cancel.y.isZero());
+
+        await assertRevert(g1.msm([p], []), 'mismatch msm input');
+    });
+});
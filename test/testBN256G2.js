@@ -0,0 +1,137 @@
+const hre = require('hardhat');
+const {assert} = require('chai');
+const bls254 = require('./blsbn254');
+const {BigNumber} = require("ethers");
+const {assertRevert, convertG2} = require('./helpers');
+
+const equalG2 = (p, q) => p.xr.eq(q.xr) && p.xi.eq(q.xi) && p.yr.eq(q.yr) && p.yi.eq(q.yi);
+const isZeroG2 = (p) => p.xr.isZero() && p.xi.isZero() && p.yr.isZero() && p.yi.isZero();
+
+function frToBN(fr) {
+    return BigNumber.from(bls254.mclToHex(fr));
+}
+
+describe('BN256G2', function () {
+    let g2;
+    const zero = {xr: 0, xi: 0, yr: 0, yi: 0};
+
+    before(async () => {
+        await bls254.init();
+        const TestBN256G2 = await hre.ethers.getContractFactory('TestBN256G2');
+        g2 = await TestBN256G2.deploy();
+        await g2.deployed();
+    });
+
+    it("should add points same as mcl", async () => {
+        for (let i = 0; i < 5; i++) {
+            const p = bls254.randG2();
+            const q = bls254.randG2();
+
+            const res = await g2.addPoints(convertG2(p), convertG2(q));
+            assert(equalG2(res, convertG2(bls254.aggreagate(p, q))));
+        }
+    });
+
+    it("should double points same as mcl", async () => {
+        const p = bls254.randG2();
+
+        assert(equalG2(await g2.doublePoint(convertG2(p)), convertG2(bls254.aggreagate(p, p))));
+        assert(equalG2(await g2.addPoints(convertG2(p), convertG2(p)), convertG2(bls254.aggreagate(p, p))));
+    });
+
+    it("should multiply points same as mcl", async () => {
+        for (let i = 0; i < 5; i++) {
+            const k = bls254.randFr();
+            const p = bls254.randG2();
+
+            const res = await g2.scalarMultiply(convertG2(p), frToBN(k));
+            assert(equalG2(res, convertG2(bls254.g2Mul(k, p))));
+        }
+
+        const k = bls254.randFr();
+        const res = await g2.scalarMultiply(convertG2(bls254.g2()), frToBN(k));
+        assert(equalG2(res, convertG2(bls254.g2Mul(k, bls254.g2()))));
+    });
+
+    it("should handle the point at infinity", async () => {
+        const p = convertG2(bls254.randG2());
+
+        assert(equalG2(await g2.addPoints(p, zero), p));
+        assert(equalG2(await g2.addPoints(zero, p), p));
+        assert(isZeroG2(await g2.doublePoint(zero)));
+        assert(isZeroG2(await g2.scalarMultiply(p, 0)));
+        assert(isZeroG2(await g2.scalarMultiply(p, bls254.ORDER)));
+        assert(equalG2(await g2.scalarMultiply(p, bls254.ORDER.add(1)), p));
+    });
+
+    it("should add a point and its negation to infinity", async () => {
+        const p = convertG2(bls254.randG2());
+        const neg = {xr: p.xr, xi: p.xi, yr: bls254.PRIME.sub(p.yr).mod(bls254.PRIME), yi: bls254.PRIME.sub(p.yi).mod(bls254.PRIME)};
+
+        assert(isZeroG2(await g2.addPoints(p, neg)));
+    });
+
+    it("should round trip compressed points", async () => {
+        for (let i = 0; i < 5; i++) {
+            const p = bls254.randG2();
+            const compressed = bls254.g2ToCompressed(p);
+
+            const res = await g2.compress(convertG2(p));
+            assert(res[0].eq(compressed[0]) && res[1].eq(compressed[1]));
+            assert(equalG2(await g2.decompress(compressed[0], compressed[1]), convertG2(p)));
+        }
+
+        const inf = await g2.compress(zero);
+        assert(isZeroG2(await g2.decompress(inf[0], inf[1])));
+    });
+
+    it("should reject invalid compressed points", async () => {
+        const p = convertG2(bls254.randG2());
+
+        await assertRevert(g2.decompress(bls254.PRIME, p.xi), 'invalid compressed G2 point');
+        await assertRevert(g2.decompress(BigNumber.from(1).shl(254), 1), 'invalid compressed G2 point');
+
+        // about half of the x coordinates are not on the twist
+        let x = p.xr;
+        for (let i = 0; i < 8; i++) {
+            x = x.add(1);
+            try {
+                await g2.decompress(x, p.xi);
+            } catch (e) {
+                assert(e.message.includes('invalid compressed G2 point'), e.message);
+                return;
+            }
+        }
+        assert.fail('expected an x coordinate off the twist');
+    });
+
+    it("should check points are on the twist and in the subgroup", async () => {
+        const p = convertG2(bls254.randG2());
+
+        assert(await g2.isOnCurveG2(p));
+        assert(await g2.isOnCurveG2(zero));
+        assert(await g2.isInSubgroupG2(p));
+        assert(await g2.isInSubgroupG2(convertG2(bls254.g2())));
+        // without the gas for a pairing a failure would say nothing about the point
+        await assertRevert(g2.isInSubgroupG2(p, {gasLimit: 150000}), 'out of gas for subgroup check');
+
+        const offCurve = {...p, yr: p.yr.add(1).mod(bls254.PRIME)};
+        assert.isFalse(await g2.isOnCurveG2(offCurve));
+        assert.isFalse(await g2.isInSubgroupG2(offCurve));
+        assert.isFalse(await g2.isOnCurveG2({...p, xr: p.xr.add(bls254.PRIME)}));
+
+        // a point decompressed from an arbitrary x lies on the twist, but the cofactor
+        // makes it almost surely miss the order r subgroup
+        for (let x = p.xr; ; x = x.add(1)) {
+            let q;
+            try {
+                q = await g2.decompress(x, p.xi);
+            } catch (e) {
+                continue;
+            }
+            assert(await g2.isOnCurveG2(q));
+            assert.isFalse(await g2.isInSubgroupG2(q));
+            break;
+        }
+    });
+});
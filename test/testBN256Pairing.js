@@ -0,0 +1,55 @@
+const hre = require('hardhat');
+const {assert} = require('chai');
+const bls254 = require('./blsbn254');
+const {assertRevert, convertG1, convertG2} = require('./helpers');
+
+const negG1 = (p) => ({x: p.x, y: bls254.PRIME.sub(p.y).mod(bls254.PRIME)});
+
+// n validators sign distinct messages, e(-sum(sig), g2) * e(H(m_1), pk_1) * ... * e(H(m_n), pk_n) == 1
+function signatureBatch(n) {
+    const a = [];
+    const b = [];
+    let aggSig;
+    for (let i = 0; i < n; i++) {
+        const key = bls254.newKeyPair();
+        const {signature, M} = bls254.sign(bls254.randHex(32), key.secret);
+        aggSig = i === 0 ? signature : bls254.aggreagate(aggSig, signature);
+        a.push(convertG1(M));
+        b.push(convertG2(key.pubkey));
+    }
+    a.unshift(negG1(convertG1(aggSig)));
+    b.unshift(convertG2(bls254.g2()));
+    return {a, b};
+}
+
+describe('BN256Pairing', function () {
+    let pairing;
+
+    before(async () => {
+        await bls254.init();
+        const TestBN256Pairing = await hre.ethers.getContractFactory('TestBN256Pairing');
+        pairing = await TestBN256Pairing.deploy();
+        await pairing.deployed();
+    });
+
+    it("should check a batch of signatures in one call", async () => {
+        for (const n of [1, 10]) {
+            const {a, b} = signatureBatch(n);
+            assert(await pairing.checkBatch(a, b));
+        }
+    });
+
+    it("should fail when any equation is off", async () => {
+        const {a, b} = signatureBatch(5);
+
+        b[3] = convertG2(bls254.randG2());
+        assert.isFalse(await pairing.checkBatch(a, b));
+    });
+
+    it("should reject mismatched or empty input", async () => {
+        const {a, b} = signatureBatch(2);
+
+        await assertRevert(pairing.checkBatch(a, b.slice(1)), 'mismatch pairing input');
+        await assertRevert(pairing.checkBatch([], []), 'empty pairing input');
+    });
+});
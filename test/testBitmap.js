@@ -0,0 +1,69 @@
+const hre = require('hardhat');
+const {assert} = require('chai');
+const {BigNumber} = require("ethers");
+const bls254 = require('./blsbn254');
+
+// words of a bitmap with the given bits set, as Bitmap reads them
+function bitmapOf(bits, words) {
+    const out = Array(words).fill(BigNumber.from(0));
+    bits.forEach(i => out[i >> 8] = out[i >> 8].or(BigNumber.from(1).shl(i & 255)));
+    return out;
+}
+
+describe('Bitmap', function () {
+    let bitmap;
+
+    before(async () => {
+        const TestBitmap = await hre.ethers.getContractFactory('TestBitmap');
+        bitmap = await TestBitmap.deploy();
+        await bitmap.deployed();
+    });
+
+    it("should read bits across words", async () => {
+        const bits = [0, 1, 255, 256, 300, 511, 700];
+        const b = bitmapOf(bits, 3);
+
+        for (const i of [0, 1, 2, 254, 255, 256, 257, 300, 511, 512, 700, 767, 768, 5000]) {
+            assert.equal(await bitmap.isBitSet(b, i), bits.includes(i), `bit ${i}`);
+        }
+        assert((await bitmap.countSetBits(b)).eq(bits.length));
+        assert.deepEqual((await bitmap.setBits(b)).map(Number), bits);
+    });
+
+    it("should iterate the set bits", async () => {
+        const bits = [3, 256, 257, 767];
+        const b = bitmapOf(bits, 3);
+
+        const seen = [];
+        for (let i = await bitmap.nextSetBit(b, 0); i.lt(768); i = await bitmap.nextSetBit(b, i.add(1))) seen.push(i.toNumber());
+        assert.deepEqual(seen, bits);
+        assert((await bitmap.nextSetBit(b, 768)).eq(768));
+        assert((await bitmap.nextSetBit([], 0)).eq(0));
+        assert.deepEqual(await bitmap.setBits([]), []);
+    });
+
+    it("should agree with a reference on random bitmaps", async () => {
+        for (const words of [1, 2, 4]) {
+            const b = Array.from({length: words}, () => BigNumber.from(bls254.randHex(32)));
+            const bits = [];
+            b.forEach((w, k) => {
+                for (let i = 0; i < 256; i++) {
+                    if (!w.shr(i).and(1).isZero()) bits.push(256 * k + i);
+                }
+            });
+            assert((await bitmap.countSetBits(b)).eq(bits.length));
+            assert.deepEqual((await bitmap.setBits(b)).map(Number), bits);
+        }
+    });
+
+    it("should check a bitmap against a set size", async () => {
+        const b = bitmapOf([10, 299], 2);
+
+        assert(await bitmap.fitsIn(b, 300));
+        assert(await bitmap.fitsIn(b, 512));
+        assert.isFalse(await bitmap.fitsIn(b, 299));
+        assert.isFalse(await bitmap.fitsIn(b, 256));
+        assert.isFalse(await bitmap.fitsIn(b, 11));
+        assert(await bitmap.fitsIn(bitmapOf([255], 2), 256));
+    });
+});
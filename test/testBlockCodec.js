@@ -0,0 +1,88 @@
+const hre = require('hardhat');
+const {assert} = require('chai');
+const {ethers} = require('hardhat');
+const bls254 = require('./blsbn254');
+const {rlpUint, encodeHeader, randomHeader, encodeExtra} = require('./header');
+const {assertRevert} = require('./helpers');
+
+const RLP = ethers.utils.RLP;
+
+// Block.EncodeRLP: [Header, Txs, Randomness, EpochSnarkData]
+function encodeBlock(h, txs, randomness, snark) {
+    return RLP.encode([RLP.decode(encodeHeader(h)), txs, randomness, snark]);
+}
+
+// a legacy transaction is a list, a typed one is type || rlp in a string
+function randomTxs(n) {
+    return Array.from({length: n}, (_, i) => i % 2 === 0
+        ? [rlpUint(i), bls254.randHex(5), bls254.randHex(3), bls254.randHex(20), '0x', bls254.randHex(40)]
+        : ethers.utils.hexConcat(['0x02', RLP.encode([bls254.randHex(2), bls254.randHex(60)])]));
+}
+
+describe('BlockCodec', function () {
+    let codec;
+
+    before(async () => {
+        const TestBlockCodec = await hre.ethers.getContractFactory('TestBlockCodec');
+        codec = await TestBlockCodec.deploy();
+        await codec.deployed();
+    });
+
+    it("should decode the header and consensus fields of a block", async () => {
+        for (const n of [0, 1, 5]) {
+            const h = randomHeader(n % 2 === 1, encodeExtra());
+            const randomness = [bls254.randHex(32), bls254.randHex(32)];
+            const snark = [rlpUint(bls254.randHex(2)), bls254.randHex(64)];
+            const block = encodeBlock(h, randomTxs(n), randomness, snark);
+
+            const b = await codec.decode(block);
+            assert.equal(b.header.parentHash, h.parentHash);
+            assert(b.header.number.eq(h.number));
+            assert.equal(b.header.extra, h.extra);
+            assert(b.txCount.eq(n));
+            assert.equal(b.randomness.revealed, randomness[0]);
+            assert.equal(b.randomness.committed, randomness[1]);
+            assert(b.epochSnarkData.bitmap.eq(snark[0]));
+            assert.equal(b.epochSnarkData.signature, snark[1]);
+
+            assert.equal(await codec.headerRlp(block), encodeHeader(h));
+            assert.equal((await codec.epochSnarkData(block)).signature, snark[1]);
+        }
+    });
+
+    it("should reject nil randomness and epoch snark data like Block.DecodeRLP", async () => {
+        const h = randomHeader(false, '0x');
+        const empty = ['0x', '0x'];
+        await assertRevert(codec.decode(encodeBlock(h, [], [], empty)), 'bad block randomness');
+        await assertRevert(codec.decode(encodeBlock(h, [], [ethers.constants.HashZero, ethers.constants.HashZero], [])), 'bad epoch snark data');
+        await assertRevert(codec.epochSnarkData(encodeBlock(h, [], [], [])), 'bad epoch snark data');
+
+        // EmptyRandomness and EmptyEpochSnarkData, zero hashes and a nil bitmap and signature
+        const b = await codec.decode(encodeBlock(h, [], [ethers.constants.HashZero, ethers.constants.HashZero], empty));
+        assert.equal(b.randomness.revealed, ethers.constants.HashZero);
+        assert(b.epochSnarkData.bitmap.isZero());
+        assert.equal(b.epochSnarkData.signature, '0x');
+    });
+
+    it("should keep the base fee and a large extra of the header", async () => {
+        const validators = Array.from({length: 40}, () => bls254.randHex(20));
+        const pubKeys = Array.from({length: 40}, () => bls254.randHex(129));
+        const h = randomHeader(true, encodeExtra({addedValidators: validators, addedPubKeys: pubKeys, seal: bls254.randHex(65)}));
+        const block = encodeBlock(h, randomTxs(3), [bls254.randHex(32), bls254.randHex(32)], [rlpUint(bls254.randHex(2)), bls254.randHex(64)]);
+
+        const b = await codec.decode(block);
+        assert(b.header.hasBaseFee && b.header.baseFee.eq(h.baseFee));
+        assert.equal(b.header.extra, h.extra);
+        assert.equal(await codec.headerRlp(block), encodeHeader(h));
+    });
+
+    it("should reject malformed blocks", async () => {
+        const h = randomHeader(false, '0x');
+        const header = RLP.decode(encodeHeader(h));
+
+        await assertRevert(codec.decode(RLP.encode([header, [], []])), 'bad block');
+        await assertRevert(codec.decode(encodeBlock(h, [], [bls254.randHex(32)], [])), 'bad block randomness');
+        await assertRevert(codec.decode(encodeBlock(h, [], [bls254.randHex(32), bls254.randHex(32)], ['0x01', '0x', '0x'])), 'bad epoch snark data');
+        await assertRevert(codec.headerRlp(RLP.encode([header, [], [], [], []])), 'bad block');
+    });
+});
@@ -0,0 +1,21 @@
+const hre = require('hardhat');
+const {assert} = require('chai');
+
+// EIP-170 limit on deployed contract code
+const MAX_CODE_SIZE = 24576;
+// room we want to keep for upcoming features before splitting into linked libraries
+const HEADROOM = 4096;
+
+describe('ContractSize', function () {
+    // LightNode links LightNodeCodec, the placeholders of its bytecode are as long as the addresses
+    const contracts = ['BGLS', 'WeightedMultiSig', 'BLSVerify', 'LightNode', 'LightNodeCodec', 'RelayerRegistry'];
+
+    contracts.forEach(name => {
+        it(`${name} should keep deployed size headroom`, async () => {
+            const artifact = await hre.artifacts.readArtifact(name);
+            const size = (artifact.deployedBytecode.length - 2) / 2;
+
+            assert(size + HEADROOM <= MAX_CODE_SIZE, `${name} deployed size is ${size} bytes`);
+        });
+    });
+});
@@ -0,0 +1,42 @@
+const fs = require('fs');
+const path = require('path');
+const hre = require('hardhat');
+const {assert} = require('chai');
+const {contractReasons, loadRegistry, check, codeOf, goSource} = require('../scripts/error-codes');
+
+describe('ErrorCodes', function () {
+    it("should register a code for every revert reason", async () => {
+        assert.deepEqual(check(), []);
+    });
+
+    it("should report unregistered reasons and duplicate codes", async () => {
+        const registry = loadRegistry();
+        const reasons = contractReasons();
+
+        reasons['brand new failure'] = new Set(['contracts/X.sol']);
+        assert.deepEqual(check(registry, reasons), ["'brand new failure' in contracts/X.sol has no code"]);
+
+        const dup = [...registry, {...registry[0], name: 'OTHER', reason: 'other'}];
+        assert.deepEqual(check(dup, contractReasons()), [`duplicate code ${registry[0].code}`]);
+    });
+
+    it("should match the committed go constants", async () => {
+        const committed = fs.readFileSync(path.join(__dirname, '..', 'errcodes', 'errcodes.go'), 'utf8');
+        assert.equal(committed, goSource(loadRegistry()), 'regenerate errcodes/errcodes.go');
+    });
+
+    it("should look up the code of a revert", async () => {
+        const registry = loadRegistry();
+        const TestBN256G1 = await hre.ethers.getContractFactory('TestBN256G1');
+        const g1 = await TestBN256G1.deploy();
+        await g1.deployed();
+
+        const data = g1.interface.encodeFunctionData('msm', [[{x: 1, y: 2}], []]);
+        // ethers returns the revert data of a call, or throws with it in older versions
+        const revert = await hre.ethers.provider.call({to: g1.address, data}).catch(e => e.data || (e.error && e.error.data));
+        assert.equal(codeOf(revert), registry.find(e => e.reason === 'mismatch msm input').code);
+
+        // a panic is not an Error(string)
+        assert.isUndefined(codeOf('0x4e487b71' + '11'.padStart(64, '0')));
+    });
+});
@@ -0,0 +1,81 @@
+const hre = require('hardhat');
+const {assert} = require('chai');
+const {ethers} = require('hardhat');
+const {BigNumber} = require("ethers");
+const {encodeEthHeader, randomEthHeader} = require('./header');
+const {assertRevert} = require('./helpers');
+
+const RLP = ethers.utils.RLP;
+const EMPTY_ROOT = '0x56e81f171bcc55a6ff8345e692c0f86e5b48e01b996cadc001622fb5e363b421';
+
+// block 0 of ethereum mainnet
+const GENESIS = {
+    parentHash: ethers.constants.HashZero,
+    uncleHash: '0x1dcc4de8dec75d7aab85b567b6ccd41ad312451b948a7413f0a142fd40d49347',
+    coinbase: ethers.constants.AddressZero,
+    root: '0xd7f8974fb5ac78d9ac099b9ad5018bedc2ce0a72dad1827a1709da30580f0544',
+    txHash: EMPTY_ROOT,
+    receiptHash: EMPTY_ROOT,
+    bloom: ethers.utils.hexZeroPad('0x', 256),
+    difficulty: BigNumber.from(0x400000000),
+    number: BigNumber.from(0),
+    gasLimit: BigNumber.from(5000),
+    gasUsed: BigNumber.from(0),
+    time: BigNumber.from(0),
+    extra: '0x11bbe8db4e347b4e8c937c1c8370e4b5ed33adb3db69cbdb7a38e1e50b1b82fa',
+    mixDigest: ethers.constants.HashZero,
+    nonce: '0x0000000000000042',
+};
+const GENESIS_HASH = '0xd4e56740f876aef8c010b86a40d5f56745a118d0906a34e69aec8c0db1cb8fa3';
+
+describe('EthHeaderCodec', function () {
+    let codec;
+
+    before(async () => {
+        const TestEthHeaderCodec = await hre.ethers.getContractFactory('TestEthHeaderCodec');
+        codec = await TestEthHeaderCodec.deploy();
+        await codec.deployed();
+    });
+
+    it("should hash the mainnet genesis header", async () => {
+        const rlp = encodeEthHeader(GENESIS);
+        assert.equal(ethers.utils.keccak256(rlp), GENESIS_HASH);
+
+        const h = await codec.decode(rlp);
+        assert(h.difficulty.eq(GENESIS.difficulty));
+        assert.equal(h.hasBaseFee, false);
+        assert.equal(await codec.hash(h), GENESIS_HASH);
+        assert.isFalse(await codec.isPostMerge(h));
+    });
+
+    it("should round trip the headers of every fork", async () => {
+        for (const fork of [0, 1, 2, 3]) {
+            const header = randomEthHeader(fork);
+            const rlp = encodeEthHeader(header);
+
+            const h = await codec.decode(rlp);
+            assert.equal(h.uncleHash, header.uncleHash);
+            assert(h.number.eq(header.number));
+            assert.equal(h.hasWithdrawalsHash, fork >= 1);
+            assert.equal(h.hasBlobGas, fork >= 2);
+            assert.equal(h.parentBeaconRoot, header.parentBeaconRoot);
+            assert.equal(h.hasRequestsHash, fork >= 3);
+            assert.equal(h.requestsHash, header.requestsHash);
+
+            assert.equal(await codec.encode(h), rlp);
+            assert.equal(await codec.hash(h), ethers.utils.keccak256(rlp));
+            assert(await codec.isPostMerge(h));
+        }
+    });
+
+    it("should reject bad headers", async () => {
+        const fields = RLP.decode(encodeEthHeader(randomEthHeader(3)));
+
+        for (const n of [14, 18, 19, 22]) {
+            const list = n <= fields.length ? fields.slice(0, n) : [...fields, ethers.constants.HashZero];
+            await assertRevert(codec.decode(RLP.encode(list)), 'bad eth header');
+        }
+        const atlas = [...fields.slice(0, 1), ...fields.slice(2, 7), ...fields.slice(8, 16)];
+        await assertRevert(codec.decode(RLP.encode(atlas)), 'bad eth header');
+    });
+});
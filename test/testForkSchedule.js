@@ -0,0 +1,95 @@
+const hre = require('hardhat');
+const {assert} = require('chai');
+const {ethers} = require('hardhat');
+const bls254 = require('./blsbn254');
+const {BigNumber} = require("ethers");
+const {NEVER, encodeHeader, headerHash, randomHeader, encodeExtra} = require('./header');
+const {assertRevert} = require('./helpers');
+
+const RLP = ethers.utils.RLP;
+const vanity = ethers.utils.hexZeroPad('0x', 32);
+
+const ATLAS = {vanity: 32, hasG1PubKeys: true};
+const CELO = {vanity: 32, hasG1PubKeys: false};
+
+// london at 100, cancun at 200, the g1 keys are dropped from the extra at 150
+const SCHEDULE = {baseFeeBlock: 100, blobGasBlock: 200, layoutBlocks: [0, 150], layouts: [ATLAS, CELO]};
+
+// header at number with the optional fields of the given forks
+function forkHeader(number, baseFee, blobGas, extra = encodeExtra()) {
+    const h = randomHeader(baseFee, extra);
+    h.number = BigNumber.from(number);
+    if (blobGas) {
+        Object.assign(h, {hasBlobGas: true, blobGasUsed: BigNumber.from(0x20000), hasParentBeaconRoot: true, parentBeaconRoot: bls254.randHex(32)});
+    }
+    return h;
+}
+
+function celoExtra(seal) {
+    return ethers.utils.hexConcat([vanity, RLP.encode([[], [], '0x', seal, ['0x01', bls254.randHex(64), '0x'], ['0x', '0x', '0x']])]);
+}
+
+describe('ForkSchedule', function () {
+    let forks;
+
+    before(async () => {
+        const TestForkSchedule = await hre.ethers.getContractFactory('TestForkSchedule');
+        forks = await TestForkSchedule.deploy();
+        await forks.deployed();
+    });
+
+    it("should require the header fields of each fork", async () => {
+        for (const [number, baseFee, blobGas] of [[0, false, false], [99, false, false], [100, true, false], [199, true, false], [200, true, true], [1000, true, true]]) {
+            const extra = number < 150 ? encodeExtra() : celoExtra(bls254.randHex(65));
+            const ok = forkHeader(number, baseFee, blobGas, extra);
+            await forks.hash(SCHEDULE, encodeHeader(ok));
+
+            const early = forkHeader(number, !baseFee, blobGas, extra);
+            if (!blobGas) await assertRevert(forks.hash(SCHEDULE, encodeHeader(early)), 'header does not match fork');
+            const late = forkHeader(number, true, !blobGas, extra);
+            await assertRevert(forks.hash(SCHEDULE, encodeHeader(late)), 'header does not match fork');
+        }
+
+        // the parent beacon root activates with the blob gas fields
+        const partial = forkHeader(200, true, true, celoExtra('0x'));
+        partial.hasParentBeaconRoot = false;
+        await assertRevert(forks.hash(SCHEDULE, encodeHeader(partial)), 'header does not match fork');
+
+        // not even the last block number reaches a fork that is never scheduled
+        const last = forkHeader(NEVER, true, false, celoExtra(bls254.randHex(65)));
+        await forks.hash({...SCHEDULE, blobGasBlock: NEVER}, encodeHeader(last));
+        await assertRevert(forks.hash({...SCHEDULE, blobGasBlock: NEVER}, encodeHeader(forkHeader(NEVER, true, true))), 'header does not match fork');
+    });
+
+    it("should read the extra with the layout of the header's fork", async () => {
+        const before = forkHeader(149, true, false);
+        assert.equal(await forks.hash(SCHEDULE, encodeHeader(before)), headerHash(before));
+
+        const seal = bls254.randHex(65);
+        const after = forkHeader(150, true, false, celoExtra(seal));
+        const filtered = ethers.utils.hexConcat([vanity, RLP.encode([[], [], '0x', seal, ['0x', '0x', '0x'], ['0x', '0x', '0x']])]);
+        assert.equal(await forks.hash(SCHEDULE, encodeHeader(after)), ethers.utils.keccak256(encodeHeader(after, filtered)));
+
+        await assertRevert(forks.hash(SCHEDULE, encodeHeader(forkHeader(149, true, false, celoExtra(seal)))), 'bad istanbul extra');
+        await assertRevert(forks.hash(SCHEDULE, encodeHeader(forkHeader(150, true, false))), 'bad istanbul extra');
+
+        for (const [number, layout] of [[0, ATLAS], [149, ATLAS], [150, CELO], [10 ** 9, CELO]]) {
+            assert.equal((await forks.layoutAt(SCHEDULE, number)).hasG1PubKeys, layout.hasG1PubKeys);
+        }
+    });
+
+    it("should reject bad schedules", async () => {
+        await forks.validate({baseFeeBlock: 0, blobGasBlock: NEVER, layoutBlocks: [0], layouts: [ATLAS]});
+
+        for (const bad of [
+            {...SCHEDULE, baseFeeBlock: 300},
+            {...SCHEDULE, layoutBlocks: [0]},
+            {...SCHEDULE, layoutBlocks: [1, 150]},
+            {...SCHEDULE, layoutBlocks: [0, 0]},
+            {...SCHEDULE, layoutBlocks: [], layouts: []},
+        ]) {
+            await assertRevert(forks.validate(bad), 'bad fork schedule');
+        }
+        await assertRevert(forks.validate({...SCHEDULE, layouts: [ATLAS, {vanity: 0, hasG1PubKeys: false}]}), 'bad extra layout');
+    });
+});
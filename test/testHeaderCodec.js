@@ -0,0 +1,176 @@
+const hre = require('hardhat');
+const {assert} = require('chai');
+const {ethers} = require('hardhat');
+const bls254 = require('./blsbn254');
+const {BigNumber} = require("ethers");
+const {headerFromJson, encodeHeader, encodeCompactHeader, filterExtra, headerHash, randomHeader, encodeExtra, committedSealMessage} = require('./header');
+
+const head = require('./testdata/head.json').result;
+const vectors = require('./testdata/vectors.json');
+const {assertRevert} = require('./helpers');
+
+describe('HeaderCodec', function () {
+    let codec;
+
+    before(async () => {
+        const TestHeaderCodec = await hre.ethers.getContractFactory('TestHeaderCodec');
+        codec = await TestHeaderCodec.deploy();
+        await codec.deployed();
+    });
+
+    it("should hash testdata header to its block hash", async () => {
+        assert.equal(await codec.hash(headerFromJson(head)), head.hash);
+    });
+
+    it("should encode headers same as go rlp", async () => {
+        for (const hasBaseFee of [false, true]) {
+            for (const extra of ['0x', '0x00', bls254.randHex(32), head.extraData]) {
+                const h = randomHeader(hasBaseFee, extra);
+                assert.equal(await codec.encode(h), encodeHeader(h));
+            }
+        }
+
+        const zero = randomHeader(true, '0x');
+        zero.number = zero.gasUsed = zero.baseFee = BigNumber.from(0);
+        assert.equal(await codec.encode(zero), encodeHeader(zero));
+    });
+
+    it("should decode what it encodes", async () => {
+        for (const hasBaseFee of [false, true]) {
+            const h = randomHeader(hasBaseFee, head.extraData);
+            const res = await codec.decode(encodeHeader(h));
+
+            assert.equal(res.hasBaseFee, hasBaseFee);
+            assert.equal(await codec.encode(res), encodeHeader(h));
+        }
+    });
+
+    it("should encode and decode the eip-4844 optional fields", async () => {
+        const h = randomHeader(true, head.extraData);
+        const blob = {...h, hasBlobGas: true, blobGasUsed: BigNumber.from(0x20000), excessBlobGas: BigNumber.from(0)};
+        const beacon = {...blob, hasParentBeaconRoot: true, parentBeaconRoot: bls254.randHex(32)};
+
+        for (const [header, count] of [[blob, 16], [beacon, 17]]) {
+            const rlp = encodeHeader(header);
+            assert.equal(ethers.utils.RLP.decode(rlp).length, count);
+            assert.equal(await codec.encode(header), rlp);
+
+            const res = await codec.decode(rlp);
+            assert.equal(res.hasBlobGas, true);
+            assert.equal(res.hasParentBeaconRoot, count === 17);
+            assert.equal(await codec.encode(res), rlp);
+            assert.equal(await codec.hash(res), headerHash(header));
+        }
+
+        // absent optional fields before a present one are encoded as zero
+        const gap = {...h, hasBaseFee: false, baseFee: BigNumber.from(0), hasParentBeaconRoot: true, parentBeaconRoot: bls254.randHex(32)};
+        const res = await codec.decode(encodeHeader(gap));
+        assert(res.hasBaseFee && res.baseFee.isZero() && res.hasBlobGas);
+
+        const fields = ethers.utils.RLP.decode(encodeHeader(blob));
+        await assertRevert(codec.decode(ethers.utils.RLP.encode(fields.slice(0, 15))), 'bad header');
+        await assertRevert(codec.decode(ethers.utils.RLP.encode([...fields, bls254.randHex(32), '0x01'])), 'bad header');
+
+        // the blob gas fields are uint64 in go
+        const max = ethers.utils.hexlify(ethers.constants.MaxUint256.shr(192));
+        await codec.decode(ethers.utils.RLP.encode([...fields.slice(0, 14), max, max]));
+        for (const i of [14, 15]) {
+            const tooLarge = [...fields];
+            tooLarge[i] = '0x010000000000000000';
+            await assertRevert(codec.decode(ethers.utils.RLP.encode(tooLarge)), 'rlp uint64 too large');
+        }
+    });
+
+    it("should reject integers with leading zeros", async () => {
+        const fields = ethers.utils.RLP.decode(encodeHeader(randomHeader(true, '0x')));
+        const withNumber = (number) => ethers.utils.RLP.encode([...fields.slice(0, 6), number, ...fields.slice(7)]);
+
+        await codec.decode(withNumber('0x01'));
+        for (const number of ['0x00', '0x0001', ethers.utils.hexZeroPad('0x01', 32)]) {
+            await assertRevert(codec.decode(withNumber(number)), 'non-canonical rlp');
+        }
+
+        // the nonce is fixed width, its leading zeros stay
+        const h = {...randomHeader(true, '0x'), nonce: '0x0000000000000001'};
+        assert.equal((await codec.decode(encodeHeader(h))).nonce, h.nonce);
+    });
+
+    it("should decode compact headers to the header of their rlp", async () => {
+        const h = randomHeader(true, head.extraData);
+        const blob = {...h, hasBlobGas: true, blobGasUsed: BigNumber.from(0x20000), excessBlobGas: BigNumber.from(0)};
+        const headers = [
+            headerFromJson(head),
+            randomHeader(false, '0x'),
+            h,
+            {...h, bloom: ethers.utils.hexZeroPad('0x', 256)},
+            blob,
+            {...blob, hasParentBeaconRoot: true, parentBeaconRoot: bls254.randHex(32)},
+        ];
+
+        for (const header of headers) {
+            const res = await codec.decodeCompact(encodeCompactHeader(header));
+            assert.equal(await codec.encode(res), encodeHeader(header));
+            assert.equal(await codec.hash(res), headerHash(header));
+        }
+        // the empty bloom is left out
+        assert.equal(ethers.utils.hexDataLength(encodeCompactHeader(headers[3])), ethers.utils.hexDataLength(encodeCompactHeader(h)) - 256);
+    });
+
+    it("should reject malformed compact headers", async () => {
+        const h = randomHeader(true, '0x');
+        const compact = encodeCompactHeader(h);
+        const withFlags = (flags) => ethers.utils.hexConcat([ethers.utils.hexlify(flags), ethers.utils.hexDataSlice(compact, 1)]);
+
+        // the base fee is cut short
+        await assertRevert(codec.decodeCompact(ethers.utils.hexDataSlice(compact, 0, ethers.utils.hexDataLength(compact) - 1)), 'bad compact header');
+        await assertRevert(codec.decodeCompact(ethers.utils.hexDataSlice(compact, 0, 220)), 'bad compact header');
+        await assertRevert(codec.decodeCompact(withFlags(16 | 1)), 'bad compact header');
+        // blob gas without the base fee, the beacon root without blob gas
+        await assertRevert(codec.decodeCompact(withFlags(2)), 'bad compact header');
+        await assertRevert(codec.decodeCompact(withFlags(1 | 4)), 'bad compact header');
+        // the empty bloom is only encoded by its flag
+        const zeroBloom = ethers.utils.hexConcat([ethers.utils.hexDataSlice(compact, 0, 149), ethers.utils.hexZeroPad('0x', 256), ethers.utils.hexDataSlice(compact, 405)]);
+        await assertRevert(codec.decodeCompact(zeroBloom), 'bad compact header');
+    });
+
+    it("should filter istanbul extra", async () => {
+        assert.equal(await codec.filterExtra(head.extraData, true), filterExtra(head.extraData, true));
+        assert.equal(await codec.filterExtra(head.extraData, false), filterExtra(head.extraData, false));
+    });
+
+    it("should hash headers without istanbul extra over the raw encoding", async () => {
+        // too short, a few bytes past the vanity, a valid extra with trailing bytes
+        for (const extra of ['0x', bls254.randHex(32), bls254.randHex(40), ethers.utils.hexConcat([encodeExtra(), '0x00'])]) {
+            const h = randomHeader(true, extra);
+            assert.equal(await codec.hash(h), ethers.utils.keccak256(encodeHeader(h)));
+        }
+
+        const h = randomHeader(true, head.extraData);
+        assert.equal(await codec.hash(h), ethers.utils.keccak256(encodeHeader(h, filterExtra(head.extraData, true))));
+    });
+
+    it("should hash headers for the proposer seal as atlas", async () => {
+        // the sig hashes in vectors.json are checked against atlas by the go tests next to it
+        for (const v of [...vectors.headers, ...vectors.invalidExtraHeaders]) {
+            assert.equal(await codec.hashHeaderForSeal(await codec.decode(v.rlp)), v.sigHash);
+        }
+
+        // the seal is all that tells the two hashes apart
+        const sealed = randomHeader(false, encodeExtra({seal: bls254.randHex(65)}));
+        const unsealed = {...sealed, extra: encodeExtra()};
+        assert.equal(await codec.hashHeaderForSeal(sealed), await codec.hashHeaderForSeal(unsealed));
+        assert.equal(await codec.hashHeaderForSeal(unsealed), await codec.hash(unsealed));
+        assert.notEqual(await codec.hashHeaderForSeal(headerFromJson(head)), head.hash);
+    });
+
+    it("should build committed seal messages as atlas", async () => {
+        for (const v of vectors.headers) {
+            assert.equal(await codec.committedSealMessage(await codec.decode(v.rlp), v.round), v.committedSealMessage);
+        }
+
+        const h = headerFromJson(head);
+        for (const round of [0, 1, 0xff, 0x100, 0x123456]) {
+            assert.equal(await codec.committedSealMessage(h, round), committedSealMessage(head.hash, round));
+        }
+    });
+});
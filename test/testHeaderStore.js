@@ -0,0 +1,57 @@
+const hre = require('hardhat');
+const {assert} = require('chai');
+const {ethers} = require('hardhat');
+const {assertRevert} = require('./helpers');
+
+async function committed(tx) {
+    const receipt = await tx.wait();
+    return receipt.events.find(e => e.event === 'Committed').args.added;
+}
+
+describe('HeaderStore', function () {
+    let store;
+
+    beforeEach(async () => {
+        const TestHeaderStore = await hre.ethers.getContractFactory('TestHeaderStore');
+        store = await TestHeaderStore.deploy();
+        await store.deployed();
+    });
+
+    it("should index headers by number and by hash", async () => {
+        const h0 = ethers.utils.hexlify(ethers.utils.randomBytes(32));
+        const h1 = ethers.utils.hexlify(ethers.utils.randomBytes(32));
+
+        assert(await committed(await store.commit(0, h0)));
+        assert(await committed(await store.commit(1, h1)));
+
+        assert.equal(await store.headerHashByNumber(0), h0);
+        assert.equal(await store.headerHashByNumber(1), h1);
+        assert.equal(await store.headerHashByNumber(2), ethers.constants.HashZero);
+
+        const [found, number] = await store.headerNumberByHash(h1);
+        assert(found && number.eq(1));
+        const [missing] = await store.headerNumberByHash(ethers.constants.HashZero);
+        assert.isFalse(missing);
+
+        assert(await store.isCommitted(0, h0));
+        assert.isFalse(await store.isCommitted(1, h0));
+        assert.isFalse(await store.isCommitted(2, ethers.constants.HashZero));
+    });
+
+    it("should accept the same header twice", async () => {
+        const h = ethers.utils.hexlify(ethers.utils.randomBytes(32));
+
+        assert(await committed(await store.commit(5, h)));
+        assert.isFalse(await committed(await store.commit(5, h)));
+    });
+
+    it("should reject conflicting headers", async () => {
+        const h = ethers.utils.hexlify(ethers.utils.randomBytes(32));
+        const other = ethers.utils.hexlify(ethers.utils.randomBytes(32));
+        await store.commit(5, h);
+
+        await assertRevert(store.commit(5, other), 'conflicting header at height');
+        await assertRevert(store.commit(6, h), 'conflicting header number');
+        await assertRevert(store.commit(7, ethers.constants.HashZero), 'empty header hash');
+    });
+});
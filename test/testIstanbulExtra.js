@@ -0,0 +1,133 @@
+const hre = require('hardhat');
+const {assert} = require('chai');
+const {ethers} = require('hardhat');
+const bls254 = require('./blsbn254');
+
+const head = require('./testdata/head.json').result;
+const {assertRevert} = require('./helpers');
+
+const vanity = ethers.utils.hexZeroPad('0x', 32);
+
+const ATLAS = {vanity: 32, hasG1PubKeys: true};
+const CELO = {vanity: 32, hasG1PubKeys: false};
+
+describe('IstanbulExtra', function () {
+    let ist;
+
+    before(async () => {
+        const TestIstanbulExtra = await hre.ethers.getContractFactory('TestIstanbulExtra');
+        ist = await TestIstanbulExtra.deploy();
+        await ist.deployed();
+    });
+
+    it("should decode extra of testdata header", async () => {
+        const fields = ethers.utils.RLP.decode(ethers.utils.hexDataSlice(head.extraData, 32));
+        const res = await ist.decode(head.extraData);
+
+        assert.equal(res.addedValidators.length, 0);
+        assert.equal(res.addedPubKeys.length, 0);
+        assert.equal(res.addedG1PubKeys.length, 0);
+        assert(res.removedValidators.isZero());
+        assert.equal(res.seal, fields[4]);
+        assert.equal(ethers.utils.hexDataLength(res.seal), 65);
+
+        assert(res.aggregatedSeal.bitmap.eq(0x07));
+        assert.equal(res.aggregatedSeal.signature, fields[5][1]);
+        assert(res.aggregatedSeal.round.isZero());
+
+        assert(res.parentAggregatedSeal.bitmap.eq(0x0f));
+        assert.equal(res.parentAggregatedSeal.signature, fields[6][1]);
+        assert(res.parentAggregatedSeal.round.isZero());
+    });
+
+    it("should decode added and removed validators", async () => {
+        const addrs = [bls254.randHex(20), bls254.randHex(20)];
+        const pks = [bls254.randHex(129), bls254.randHex(129)];
+        const g1pks = [bls254.randHex(64), bls254.randHex(64)];
+        const seal = bls254.randHex(65);
+        const aggSig = bls254.randHex(64);
+        const parentSig = bls254.randHex(64);
+
+        const extra = ethers.utils.hexConcat([vanity, ethers.utils.RLP.encode([
+            addrs, pks, g1pks, '0x05', seal, ['0x03', aggSig, '0x01'], ['0x0f', parentSig, '0x'],
+        ])]);
+        const res = await ist.decode(extra);
+
+        assert.deepEqual(res.addedValidators.map(a => a.toLowerCase()), addrs);
+        assert.deepEqual(res.addedPubKeys, pks);
+        assert.deepEqual(res.addedG1PubKeys, g1pks);
+        assert(res.removedValidators.eq(5));
+        assert.equal(res.seal, seal);
+        assert(res.aggregatedSeal.bitmap.eq(3));
+        assert.equal(res.aggregatedSeal.signature, aggSig);
+        assert(res.aggregatedSeal.round.eq(1));
+        assert(res.parentAggregatedSeal.bitmap.eq(0x0f));
+        assert.equal(res.parentAggregatedSeal.signature, parentSig);
+        assert(res.parentAggregatedSeal.round.isZero());
+    });
+
+    it("should reject malformed extra", async () => {
+        await assertRevert(ist.decode(vanity), 'extra too short');
+        await assertRevert(ist.decode(head.extraData.slice(0, -2)), 'rlp item out of bounds');
+
+        const sixFields = ethers.utils.hexConcat([vanity, ethers.utils.RLP.encode([[], [], [], '0x', '0x', ['0x', '0x', '0x']])]);
+        await assertRevert(ist.decode(sixFields), 'bad istanbul extra');
+
+        const badSeal = ethers.utils.hexConcat([vanity, ethers.utils.RLP.encode([[], [], [], '0x', '0x', ['0x', '0x'], ['0x', '0x', '0x']])]);
+        await assertRevert(ist.decode(badSeal), 'bad aggregated seal');
+
+        const mismatch = ethers.utils.hexConcat([vanity, ethers.utils.RLP.encode([[bls254.randHex(20)], [], [], '0x', '0x', ['0x', '0x', '0x'], ['0x', '0x', '0x']])]);
+        await assertRevert(ist.decode(mismatch), 'mismatch added validators');
+    });
+
+    it("should decode celo extra without g1 keys", async () => {
+        const addrs = [bls254.randHex(20)];
+        const pks = [bls254.randHex(129)];
+        const seal = bls254.randHex(65);
+        const aggSig = bls254.randHex(64);
+
+        const extra = ethers.utils.hexConcat([vanity, ethers.utils.RLP.encode([
+            addrs, pks, '0x02', seal, ['0x01', aggSig, '0x'], ['0x03', '0x', '0x'],
+        ])]);
+        const res = await ist.decodeWithLayout(extra, CELO);
+
+        assert.deepEqual(res.addedValidators.map(a => a.toLowerCase()), addrs);
+        assert.deepEqual(res.addedPubKeys, pks);
+        assert.equal(res.addedG1PubKeys.length, 0);
+        assert(res.removedValidators.eq(2));
+        assert.equal(res.seal, seal);
+        assert(res.aggregatedSeal.bitmap.eq(1));
+        assert.equal(res.aggregatedSeal.signature, aggSig);
+        assert(res.parentAggregatedSeal.bitmap.eq(3));
+
+        await assertRevert(ist.decodeWithLayout(extra, ATLAS), 'bad istanbul extra');
+        await assertRevert(ist.decodeWithLayout(head.extraData, CELO), 'bad istanbul extra');
+
+        const filtered = await ist.filter(extra, false, CELO);
+        assert.equal(filtered, ethers.utils.hexConcat([vanity, ethers.utils.RLP.encode([
+            addrs, pks, '0x02', '0x', ['0x', '0x', '0x'], ['0x03', '0x', '0x'],
+        ])]));
+    });
+
+    it("should decode extra with a custom vanity", async () => {
+        const longVanity = bls254.randHex(64);
+        const seal = bls254.randHex(65);
+        const fields = [[], [], [], '0x', seal, ['0x07', bls254.randHex(64), '0x'], ['0x0f', bls254.randHex(64), '0x']];
+        const extra = ethers.utils.hexConcat([longVanity, ethers.utils.RLP.encode(fields)]);
+
+        const res = await ist.decodeWithLayout(extra, {vanity: 64, hasG1PubKeys: true});
+        assert.equal(res.seal, seal);
+        assert(res.aggregatedSeal.bitmap.eq(7));
+        assert(res.parentAggregatedSeal.bitmap.eq(0x0f));
+
+        fields[5] = ['0x', '0x', '0x'];
+        const filtered = await ist.filter(extra, true, {vanity: 64, hasG1PubKeys: true});
+        assert.equal(filtered, ethers.utils.hexConcat([longVanity, ethers.utils.RLP.encode(fields)]));
+    });
+
+    it("should reject bad layouts", async () => {
+        await assertRevert(ist.decodeWithLayout(head.extraData, {vanity: 0, hasG1PubKeys: true}), 'bad extra layout');
+        await assertRevert(ist.decodeWithLayout(head.extraData, {vanity: 257, hasG1PubKeys: true}), 'bad extra layout');
+        await assertRevert(ist.filter(head.extraData, true, {vanity: 0, hasG1PubKeys: false}), 'bad extra layout');
+    });
+});
@@ -0,0 +1,401 @@
+const hre = require('hardhat');
+const {assert} = require('chai');
+const {ethers} = require('hardhat');
+const mcl = require('mcl-wasm');
+const bls254 = require('./blsbn254');
+const {BigNumber} = require("ethers");
+const {NEVER, SEAL_DST, chainConfig, encodeHeader, encodeCompactHeader, headerHash, randomHeader, encodeExtra, committedSealMessage} = require('./header');
+const {assertRevert, convertG1, convertG2, lightNodeFactory} = require('./helpers');
+
+// bn256 marshalling used in the istanbul extra: xi || xr || yi || yr
+function marshalG2(mclG2) {
+    const hex = bls254.g2ToHex(mclG2);
+    return ethers.utils.hexConcat([hex[1], hex[0], hex[3], hex[2]]);
+}
+
+const EPOCH_SIZE = 4;
+
+describe('LightNode', function () {
+    let node;
+    let keys;
+    let genesisHash;
+    // the tests submit as the registry, for relayer
+    let registry;
+    let relayer;
+
+    // header number on top of parent, sealed by keys[i] for every i in signers
+    function sealHeader(parentHash, number, signers, ist = {}, round = 0, hasBaseFee = true) {
+        const h = randomHeader(hasBaseFee, encodeExtra(ist));
+        h.parentHash = parentHash;
+        h.number = BigNumber.from(number);
+
+        const hash = headerHash(h);
+        const message = committedSealMessage(hash, round);
+        let bitmap = BigNumber.from(0);
+        let sig;
+        signers.forEach(i => {
+            const s = bls254.signWithDST(SEAL_DST, message, keys[i].secret).signature;
+            sig = sig ? bls254.aggreagate(sig, s) : s;
+            bitmap = bitmap.or(BigNumber.from(1).shl(i));
+        });
+
+        const seal = {bitmap, signature: ethers.utils.hexConcat(bls254.g1ToHex(sig)), round};
+        h.extra = encodeExtra({...ist, aggregatedSeal: seal});
+        return {rlp: encodeHeader(h), hash, header: h, seal};
+    }
+
+    async function submitChain(count, signers = [0, 1, 2]) {
+        let [, head] = await node.verifiableHeaderRange();
+        let parent = await node.headHash();
+        for (let i = 0; i < count; i++) {
+            head = head.add(1);
+            const h = sealHeader(parent, head, signers);
+            await node.submitHeader(h.rlp, relayer.address);
+            parent = h.hash;
+        }
+        return parent;
+    }
+
+    beforeEach(async () => {
+        await bls254.init();
+        [registry, relayer] = await ethers.getSigners();
+        keys = Array.from({length: 4}, () => bls254.newKeyPair());
+        genesisHash = bls254.randHex(32);
+
+        const LightNode = await lightNodeFactory();
+        node = await LightNode.deploy(chainConfig(EPOCH_SIZE), 0, genesisHash, keys.map(k => convertG2(k.pubkey)), registry.address);
+        await node.deployed();
+    });
+
+    it("should import a sealed header chain", async () => {
+        const h1 = sealHeader(genesisHash, 1, [0, 1, 2]);
+        await node.submitHeader(h1.rlp, relayer.address);
+        const h2 = sealHeader(h1.hash, 2, [1, 2, 3], {}, 3);
+        await node.submitHeader(h2.rlp, relayer.address);
+
+        const [start, end] = await node.verifiableHeaderRange();
+        assert(start.eq(0) && end.eq(2));
+        assert.equal(await node.headHash(), h2.hash);
+        assert.equal(await node.headerHashByNumber(0), genesisHash);
+        assert.equal(await node.headerHashByNumber(1), h1.hash);
+        assert(await node.isHeaderVerified(2, h2.hash));
+        assert.isFalse(await node.isHeaderVerified(2, h1.hash));
+    });
+
+    it("should import height 1 on top of genesis only", async () => {
+        await assertRevert(node.submitHeader(sealHeader(bls254.randHex(32), 0, [0, 1, 2]).rlp, relayer.address), 'unexpected header number');
+        await assertRevert(node.submitHeader(sealHeader(genesisHash, 0, [0, 1, 2]).rlp, relayer.address), 'unexpected header number');
+
+        const h1 = sealHeader(genesisHash, 1, [0, 1, 2]);
+        await node.submitHeader(h1.rlp, relayer.address);
+        assert(await node.isHeaderVerified(0, genesisHash));
+        assert(await node.isHeaderVerified(1, h1.hash));
+        assert.equal(await node.firstNumber(), 0);
+    });
+
+    it("should take submissions from its registry only", async () => {
+        const h1 = sealHeader(genesisHash, 1, [0, 1, 2]);
+        await assertRevert(node.connect(relayer).submitHeader(h1.rlp, relayer.address), 'only registry');
+        await assertRevert(node.connect(relayer).submitCompactHeader(encodeCompactHeader(h1.header), relayer.address), 'only registry');
+        assert.equal(await node.registry(), registry.address);
+        assert.equal(await node.headHash(), genesisHash);
+    });
+
+    it("should record who proved each header", async () => {
+        const h1 = sealHeader(genesisHash, 1, [0, 1, 2]);
+        const tx = await node.submitHeader(h1.rlp, relayer.address);
+        const receipt = await tx.wait();
+
+        const proved = receipt.events.find(e => e.event === 'HeaderProved').args;
+        assert.equal(proved.hash, h1.hash);
+        assert.equal(proved.relayer, relayer.address);
+        assert.equal(proved.submission, ethers.utils.keccak256(h1.rlp));
+        assert(proved.gasUsed.gt(0) && proved.gasUsed.lt(receipt.gasUsed));
+
+        assert.equal(await node.whoProved(h1.hash), relayer.address);
+        assert.equal(await node.whoProved(genesisHash), ethers.constants.AddressZero);
+        assert.equal(await node.whoProved(bls254.randHex(32)), ethers.constants.AddressZero);
+    });
+
+    it("should accept headers in the compact encoding", async () => {
+        const h1 = sealHeader(genesisHash, 1, [0, 1, 2]);
+        const compact = encodeCompactHeader(h1.header);
+        const tx = await node.submitCompactHeader(compact, relayer.address);
+        const proved = (await tx.wait()).events.find(e => e.event === 'HeaderProved').args;
+        assert.equal(proved.hash, h1.hash);
+        assert.equal(proved.submission, ethers.utils.keccak256(compact));
+        assert.equal(await node.headHash(), h1.hash);
+
+        // the seal is checked the same way
+        const h2 = sealHeader(h1.hash, 2, [0, 3]);
+        await assertRevert(node.submitCompactHeader(encodeCompactHeader(h2.header), relayer.address), 'not enough signers');
+        await node.submitHeader(sealHeader(h1.hash, 2, [0, 1, 2]).rlp, relayer.address);
+    });
+
+    it("should tell why a header is rejected", async () => {
+        const h1 = sealHeader(genesisHash, 1, [0, 1, 2]);
+        const badBitmap = sealHeader(genesisHash, 1, [0, 1, 2]);
+        badBitmap.header.extra = encodeExtra({aggregatedSeal: {...badBitmap.seal, bitmap: badBitmap.seal.bitmap.or(16)}});
+        const otherSeal = ethers.utils.RLP.decode(h1.rlp);
+        otherSeal[10] = ethers.utils.RLP.decode(sealHeader(genesisHash, 1, [0, 1, 2]).rlp)[10];
+
+        // Valid, UnexpectedNumber, ParentMismatch, BadSealBitmap, NotEnoughSigners, InvalidSeal
+        const statuses = [
+            [h1.rlp, 0],
+            [sealHeader(genesisHash, 2, [0, 1, 2]).rlp, 1],
+            [sealHeader(bls254.randHex(32), 1, [0, 1, 2]).rlp, 2],
+            [encodeHeader(badBitmap.header), 3],
+            [sealHeader(genesisHash, 1, [0, 3]).rlp, 4],
+            [ethers.utils.RLP.encode(otherSeal), 5],
+        ];
+        for (const [rlp, status] of statuses) {
+            assert.equal(await node.callStatic.verifyHeader(rlp), status);
+        }
+        assert.equal(await node.callStatic.verifyCompactHeader(encodeCompactHeader(h1.header)), 0);
+        assert.equal(await node.callStatic.verifyCompactHeader(encodeCompactHeader(badBitmap.header)), 3);
+
+        // a header that does not decode has no status
+        await assertRevert(node.callStatic.verifyHeader(ethers.utils.RLP.encode(['0x01'])), 'bad header');
+        assert.equal(await node.headHash(), genesisHash);
+    });
+
+    it("should reject headers that do not extend the head", async () => {
+        await assertRevert(node.submitHeader(sealHeader(genesisHash, 2, [0, 1, 2]).rlp, relayer.address), 'unexpected header number');
+        await assertRevert(node.submitHeader(sealHeader(bls254.randHex(32), 1, [0, 1, 2]).rlp, relayer.address), 'parent hash mismatch');
+    });
+
+    it("should require a 2/3 quorum of signers", async () => {
+        await assertRevert(node.submitHeader(sealHeader(genesisHash, 1, [0, 3]).rlp, relayer.address), 'not enough signers');
+
+        // bit 4 is past the 4 validators
+        const h = sealHeader(genesisHash, 1, [0, 1, 2]);
+        h.header.extra = encodeExtra({aggregatedSeal: {...h.seal, bitmap: h.seal.bitmap.or(16)}});
+        await assertRevert(node.submitHeader(encodeHeader(h.header), relayer.address), 'bad seal bitmap');
+
+        await node.submitHeader(sealHeader(genesisHash, 1, [0, 1, 3]).rlp, relayer.address);
+    });
+
+    it("should reject seal signatures that are not points on G1", async () => {
+        const h = sealHeader(genesisHash, 1, [0, 1, 2]);
+        const x = BigNumber.from(ethers.utils.hexDataSlice(h.seal.signature, 0, 32));
+        const y = BigNumber.from(ethers.utils.hexDataSlice(h.seal.signature, 32));
+        const signatures = [
+            // outside the field, off the curve, cut short
+            ethers.utils.hexConcat([bls254.bigToHex(x.add(bls254.PRIME)), bls254.bigToHex(y)]),
+            ethers.utils.hexConcat([bls254.bigToHex(x), bls254.bigToHex(y.add(1))]),
+            ethers.utils.hexDataSlice(h.seal.signature, 1),
+        ];
+        for (const signature of signatures) {
+            h.header.extra = encodeExtra({aggregatedSeal: {...h.seal, signature}});
+            assert.equal(await node.callStatic.verifyHeader(encodeHeader(h.header)), 5);
+            await assertRevert(node.submitHeader(encodeHeader(h.header), relayer.address), 'invalid aggregated seal');
+        }
+    });
+
+    it("should reject a seal over another header", async () => {
+        const h = sealHeader(genesisHash, 1, [0, 1, 2]);
+        const other = sealHeader(genesisHash, 1, [0, 1, 2]);
+
+        // same header, aggregated seal of a different header
+        const fields = ethers.utils.RLP.decode(h.rlp);
+        fields[10] = ethers.utils.RLP.decode(other.rlp)[10];
+        await assertRevert(node.submitHeader(ethers.utils.RLP.encode(fields), relayer.address), 'invalid aggregated seal');
+    });
+
+    it("should reject a seal moved to another header", async () => {
+        const h = sealHeader(genesisHash, 1, [0, 1, 2]);
+        const forged = sealHeader(genesisHash, 1, [0, 1, 2]);
+        const message = committedSealMessage(h.hash, 0);
+        const forgedMessage = committedSealMessage(forged.hash, 0);
+
+        // hashToG1 is keccak(m) * g1, so a signature over it moves to any message m' as (h' / h) * sig
+        const scalar = (m) => {
+            const fr = new mcl.Fr();
+            fr.setStr(BigNumber.from(ethers.utils.keccak256(m)).mod(bls254.ORDER).toString());
+            return fr;
+        };
+        const ratio = mcl.div(scalar(forgedMessage), scalar(message));
+        const move = (sig) => {
+            const moved = mcl.mul(sig, ratio);
+            moved.normalize();
+            return moved;
+        };
+        const sealed = (sig) => {
+            forged.header.extra = encodeExtra({aggregatedSeal: {...forged.seal, signature: ethers.utils.hexConcat(bls254.g1ToHex(sig))}});
+            return encodeHeader(forged.header);
+        };
+
+        let plain;
+        let tagged;
+        let key;
+        [0, 1, 2].forEach(i => {
+            const s = bls254.sign(message, keys[i].secret).signature;
+            const t = bls254.signWithDST(SEAL_DST, message, keys[i].secret).signature;
+            plain = plain ? bls254.aggreagate(plain, s) : s;
+            tagged = tagged ? bls254.aggreagate(tagged, t) : t;
+            key = key ? bls254.aggreagate(key, keys[i].pubkey) : keys[i].pubkey;
+        });
+
+        // the moved seal checks over hashToG1, seals are not checked with it
+        assert(await node.callStatic.checkSignature(forgedMessage, convertG1(move(plain)), convertG2(key)));
+        assert.equal(await node.callStatic.verifyHeader(sealed(move(plain))), 5);
+        assert.equal(await node.callStatic.verifyHeader(sealed(move(tagged))), 5);
+        await assertRevert(node.submitHeader(sealed(move(tagged)), relayer.address), 'invalid aggregated seal');
+    });
+
+    it("should rotate validators at the epoch boundary", async () => {
+        const parent = await submitChain(EPOCH_SIZE - 1);
+
+        const added = bls254.newKeyPair();
+        const ist = {addedValidators: [bls254.randHex(20)], addedPubKeys: [marshalG2(added.pubkey)], removedValidators: 1};
+        const last = sealHeader(parent, EPOCH_SIZE, [0, 1, 2], ist);
+        await node.submitHeader(last.rlp, relayer.address);
+
+        // keys[1], keys[2], keys[3], added
+        const set = await node.getValidators();
+        assert.equal(set.length, 4);
+        assert(set[0].xr.eq(convertG2(keys[1].pubkey).xr));
+        assert(set[3].xr.eq(convertG2(added.pubkey).xr));
+
+        await assertRevert(node.submitHeader(sealHeader(last.hash, EPOCH_SIZE + 1, [0, 1, 2]).rlp, relayer.address), 'invalid aggregated seal');
+        keys = [keys[1], keys[2], keys[3], added];
+        await node.submitHeader(sealHeader(last.hash, EPOCH_SIZE + 1, [1, 2, 3]).rlp, relayer.address);
+    });
+
+    it("should keep the genesis validators for the whole first epoch", async () => {
+        const parent = await submitChain(EPOCH_SIZE - 1);
+        assert.equal((await node.getValidators()).length, 4);
+
+        // the first epoch's last header elects the set of the second epoch without removing anyone
+        const added = bls254.newKeyPair();
+        const ist = {addedValidators: [bls254.randHex(20)], addedPubKeys: [marshalG2(added.pubkey)]};
+        const last = sealHeader(parent, EPOCH_SIZE, [0, 1, 2], ist);
+        const tx = await node.submitHeader(last.rlp, relayer.address);
+        const updated = (await tx.wait()).events.find(e => e.event === 'ValidatorSetUpdated');
+        assert(updated.args.number.eq(EPOCH_SIZE) && updated.args.size.eq(5));
+
+        // 3 of 5 is short of the quorum of the second epoch
+        await assertRevert(node.submitHeader(sealHeader(last.hash, EPOCH_SIZE + 1, [0, 1, 2]).rlp, relayer.address), 'not enough signers');
+        keys.push(added);
+        await node.submitHeader(sealHeader(last.hash, EPOCH_SIZE + 1, [0, 1, 4]).rlp, relayer.address);
+    });
+
+    it("should start from the last header of a later epoch", async () => {
+        const LightNode = await lightNodeFactory();
+        const start = 3 * EPOCH_SIZE;
+        node = await LightNode.deploy(chainConfig(EPOCH_SIZE), start, genesisHash, keys.map(k => convertG2(k.pubkey)), registry.address);
+        await node.deployed();
+
+        const [first, head] = await node.verifiableHeaderRange();
+        assert(first.eq(start) && head.eq(start));
+        await assertRevert(node.submitHeader(sealHeader(genesisHash, 1, [0, 1, 2]).rlp, relayer.address), 'unexpected header number');
+        await node.submitHeader(sealHeader(genesisHash, start + 1, [0, 1, 2]).rlp, relayer.address);
+    });
+
+    it("should reject invalid validator keys at the epoch boundary", async () => {
+        const parent = await submitChain(EPOCH_SIZE - 1);
+
+        const p = bls254.randG2();
+        const hex = bls254.g2ToHex(p);
+        const offCurve = ethers.utils.hexConcat([hex[1], hex[0], bls254.bigToHex(BigNumber.from(hex[3]).add(1)), hex[2]]);
+        const ist = {addedValidators: [bls254.randHex(20)], addedPubKeys: [offCurve]};
+        await assertRevert(node.submitHeader(sealHeader(parent, EPOCH_SIZE, [0, 1, 2], ist).rlp, relayer.address), 'bad G2 encoding');
+
+        // xi + p reduces to the same key
+        const nonCanonical = ethers.utils.hexConcat([bls254.bigToHex(BigNumber.from(hex[1]).add(bls254.PRIME)), hex[0], hex[3], hex[2]]);
+        const aliased = {addedValidators: [bls254.randHex(20)], addedPubKeys: [nonCanonical]};
+        await assertRevert(node.submitHeader(sealHeader(parent, EPOCH_SIZE, [0, 1, 2], aliased).rlp, relayer.address), 'bad G2 encoding');
+
+        // the flag byte after the key is zero
+        const flagged = {addedValidators: [bls254.randHex(20)], addedPubKeys: [ethers.utils.hexConcat([marshalG2(p), '0x01'])]};
+        await assertRevert(node.submitHeader(sealHeader(parent, EPOCH_SIZE, [0, 1, 2], flagged).rlp, relayer.address), 'bad G2 encoding');
+
+        const empty = {removedValidators: 0x0f};
+        await assertRevert(node.submitHeader(sealHeader(parent, EPOCH_SIZE, [0, 1, 2], empty).rlp, relayer.address), 'empty validator set');
+    });
+
+    it("should follow the fork schedule of its chain config", async () => {
+        const LightNode = await lightNodeFactory();
+        const config = chainConfig(EPOCH_SIZE, {baseFeeBlock: 2});
+        node = await LightNode.deploy(config, 0, genesisHash, keys.map(k => convertG2(k.pubkey)), registry.address);
+        await node.deployed();
+
+        const stored = await node.chainConfig();
+        assert(stored.epochSize.eq(EPOCH_SIZE) && stored.forks.baseFeeBlock.eq(2) && stored.forks.blobGasBlock.eq(NEVER));
+        assert.equal(stored.forks.layouts.length, 1);
+
+        // before london
+        await assertRevert(node.submitHeader(sealHeader(genesisHash, 1, [0, 1, 2]).rlp, relayer.address), 'header does not match fork');
+        const first = sealHeader(genesisHash, 1, [0, 1, 2], {}, 0, false);
+        await node.submitHeader(first.rlp, relayer.address);
+
+        // from london on
+        await assertRevert(node.submitHeader(sealHeader(first.hash, 2, [0, 1, 2], {}, 0, false).rlp, relayer.address), 'header does not match fork');
+        await node.submitHeader(sealHeader(first.hash, 2, [0, 1, 2]).rlp, relayer.address);
+    });
+
+    it("should store only checkpoints and the head", async () => {
+        const LightNode = await lightNodeFactory();
+        node = await LightNode.deploy(chainConfig(EPOCH_SIZE, {}, 3), 0, genesisHash, keys.map(k => convertG2(k.pubkey)), registry.address);
+        await node.deployed();
+        assert((await node.chainConfig()).checkpointInterval.eq(3));
+
+        // checkpoints at 0, 3, 4 (epoch boundary) and 6, head at 7
+        const chain = [{hash: genesisHash}];
+        for (let i = 1; i <= 7; i++) {
+            chain.push(sealHeader(chain[i - 1].hash, i, [0, 1, 2]));
+            await node.submitHeader(chain[i].rlp, relayer.address);
+        }
+
+        for (const i of [3, 4, 6, 7]) assert.equal(await node.headerHashByNumber(i), chain[i].hash);
+        for (const i of [1, 2, 5]) {
+            assert.equal(await node.headerHashByNumber(i), ethers.constants.HashZero);
+            assert.isFalse(await node.isHeaderVerified(i, chain[i].hash));
+        }
+        // who proved a header is stored with the checkpoints, and logged for every header
+        for (const i of [3, 4, 6]) assert.equal(await node.whoProved(chain[i].hash), relayer.address);
+        for (const i of [1, 2, 5, 7]) assert.equal(await node.whoProved(chain[i].hash), ethers.constants.AddressZero);
+        for (let i = 1; i <= 7; i++) {
+            const [proved] = await node.queryFilter(node.filters.HeaderProved(chain[i].hash));
+            assert.equal(proved.args.relayer, relayer.address);
+        }
+
+        // pruned headers are proven by linking them to a checkpoint or the head
+        const segment = (from, to) => chain.slice(from, to + 1).map(h => h.rlp);
+        let [number, hash] = await node.proveHeaderBetweenCheckpoints(segment(1, 3));
+        assert(number.eq(1) && hash === chain[1].hash);
+        [number, hash] = await node.proveHeaderBetweenCheckpoints(segment(5, 7));
+        assert(number.eq(5) && hash === chain[5].hash);
+
+        await assertRevert(node.proveHeaderBetweenCheckpoints([]), 'empty header segment');
+        await assertRevert(node.proveHeaderBetweenCheckpoints(segment(1, 2)), 'header segment not anchored');
+        await assertRevert(node.proveHeaderBetweenCheckpoints([chain[1].rlp, chain[3].rlp]), 'broken header segment');
+        const forged = sealHeader(chain[1].hash, 2, [0, 1, 2]);
+        await assertRevert(node.proveHeaderBetweenCheckpoints([chain[1].rlp, forged.rlp, chain[3].rlp]), 'broken header segment');
+    });
+
+    it("should reject a bad chain config", async () => {
+        const LightNode = await lightNodeFactory();
+        const pubkeys = keys.map(k => convertG2(k.pubkey));
+        await assertRevert(LightNode.deploy(chainConfig(EPOCH_SIZE, {baseFeeBlock: 10, blobGasBlock: 5}), 0, genesisHash, pubkeys, registry.address), 'bad fork schedule');
+        await assertRevert(LightNode.deploy(chainConfig(EPOCH_SIZE, {layoutBlocks: [1]}), 0, genesisHash, pubkeys, registry.address), 'bad fork schedule');
+        await assertRevert(LightNode.deploy(chainConfig(EPOCH_SIZE, {layouts: [{vanity: 0, hasG1PubKeys: true}]}), 0, genesisHash, pubkeys, registry.address), 'bad extra layout');
+        await assertRevert(LightNode.deploy(chainConfig(EPOCH_SIZE, {}, 0), 0, genesisHash, pubkeys, registry.address), 'bad checkpoint interval');
+    });
+
+    it("should pass its self test", async () => {
+        await node.callStatic.selfTest();
+    });
+
+    it("should reject a bad initial validator set", async () => {
+        const LightNode = await lightNodeFactory();
+        await assertRevert(LightNode.deploy(chainConfig(EPOCH_SIZE), 0, genesisHash, [], registry.address), 'empty validator set');
+        await assertRevert(LightNode.deploy(chainConfig(0), 0, genesisHash, keys.map(k => convertG2(k.pubkey)), registry.address), 'bad epoch size');
+        await assertRevert(LightNode.deploy(chainConfig(EPOCH_SIZE), 1, genesisHash, keys.map(k => convertG2(k.pubkey)), registry.address), 'trusted header not at epoch boundary');
+        await assertRevert(LightNode.deploy(chainConfig(EPOCH_SIZE), EPOCH_SIZE + 2, genesisHash, keys.map(k => convertG2(k.pubkey)), registry.address), 'trusted header not at epoch boundary');
+
+        const bad = convertG2(keys[0].pubkey);
+        bad.yr = bad.yr.add(1).mod(bls254.PRIME);
+        await assertRevert(LightNode.deploy(chainConfig(EPOCH_SIZE), 0, genesisHash, [bad], registry.address), 'invalid validator key');
+    });
+});
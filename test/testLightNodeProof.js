@@ -0,0 +1,64 @@
+const hre = require('hardhat');
+const {assert} = require('chai');
+const {ethers} = require('hardhat');
+const bls254 = require('./blsbn254');
+const {Trie} = require('./mpt');
+const {chainConfig} = require('./header');
+const {convertG2, lightNodeFactory} = require('./helpers');
+
+const RLP = ethers.utils.RLP;
+
+// storage values are rlp of the big endian value without leading zeros
+const storageValue = (v) => RLP.encode(ethers.utils.hexStripZeros(v));
+
+describe('LightNodeProof', function () {
+    let proof;
+    let node;
+    let genesisHash;
+
+    before(async () => {
+        await bls254.init();
+        const TestLightNodeProof = await hre.ethers.getContractFactory('TestLightNodeProof');
+        proof = await TestLightNodeProof.deploy();
+        await proof.deployed();
+
+        genesisHash = bls254.randHex(32);
+        const keys = Array.from({length: 4}, () => bls254.newKeyPair());
+        const LightNode = await lightNodeFactory();
+        node = await LightNode.deploy(chainConfig(7), 7, genesisHash, keys.map(k => convertG2(k.pubkey)), ethers.constants.AddressZero);
+        await node.deployed();
+    });
+
+    it("should use the storage slot of LightNode", async () => {
+        for (const number of [0, 7, 123456]) {
+            assert.equal(await proof.headerHashSlot(number), await node.headerHashSlot(number));
+        }
+        // the slot of the trusted header holds its hash, so HEADERS_SLOT is where LightNode.headers is
+        const slot = await proof.headerHashSlot(7);
+        assert.equal(await ethers.provider.getStorageAt(node.address, slot), genesisHash);
+        assert.equal(await ethers.provider.getStorageAt(node.address, await proof.headerHashSlot(8)), ethers.constants.HashZero);
+    });
+
+    it("should verify stored header hashes against a state root", async () => {
+        const hashes = {7: genesisHash, 8: bls254.randHex(32), 9: ethers.utils.hexZeroPad('0x01', 32)};
+        const slots = {};
+        for (const number of [7, 8, 9, 10]) slots[number] = await proof.headerHashSlot(number);
+
+        const storage = new Trie(Object.entries(hashes).map(([n, h]) => [ethers.utils.keccak256(slots[n]), storageValue(h)]));
+        const accountKey = ethers.utils.keccak256(node.address);
+        const state = new Trie([
+            [accountKey, RLP.encode(['0x01', '0x', storage.rootHash(), ethers.utils.keccak256('0x')])],
+            [ethers.utils.keccak256(bls254.randHex(20)), RLP.encode(['0x', '0x', storage.rootHash(), ethers.utils.keccak256('0x')])],
+        ]);
+
+        for (const number of [7, 8, 9]) {
+            const res = await proof.verifyHeaderHash(
+                state.rootHash(), node.address, number, state.prove(accountKey), storage.prove(ethers.utils.keccak256(slots[number])));
+            assert.equal(res, hashes[number]);
+        }
+
+        const absent = await proof.verifyHeaderHash(
+            state.rootHash(), node.address, 10, state.prove(accountKey), storage.prove(ethers.utils.keccak256(slots[10])));
+        assert.equal(absent, ethers.constants.HashZero);
+    });
+});
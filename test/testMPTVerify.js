@@ -0,0 +1,128 @@
+const hre = require('hardhat');
+const {assert} = require('chai');
+const {ethers} = require('hardhat');
+const bls254 = require('./blsbn254');
+const {Trie, indexKey, packProofs} = require('./mpt');
+const {assertRevert} = require('./helpers');
+// proofs built by ReceiptProof in test/testdata/prove.go
+const receiptProofs = require('./testdata/receipt_proofs.json');
+
+const utf8 = (s) => ethers.utils.hexlify(ethers.utils.toUtf8Bytes(s));
+
+// legacy receipts are plain rlp lists, typed receipts are type || rlp
+function randomReceipt(i) {
+    const receipt = ethers.utils.RLP.encode(['0x01', bls254.randHex(3), bls254.randHex(256), [[bls254.randHex(20), [bls254.randHex(32)], bls254.randHex(i % 3 * 40)]]]);
+    return i % 2 === 0 ? receipt : ethers.utils.hexConcat(['0x02', receipt]);
+}
+
+describe('MPTVerify', function () {
+    let mpt;
+
+    before(async () => {
+        const TestMPTVerify = await hre.ethers.getContractFactory('TestMPTVerify');
+        mpt = await TestMPTVerify.deploy();
+        await mpt.deployed();
+    });
+
+    it("should build the reference trie", async () => {
+        const trie = new Trie([['do', 'verb'], ['dog', 'puppy'], ['doge', 'coin'], ['horse', 'stallion']].map(([k, v]) => [utf8(k), utf8(v)]));
+        assert.equal(trie.rootHash(), '0x5991bb8c6514148a29db676a14ac506cd2cd5775ace63c30a4fe457715e9ac84');
+    });
+
+    it("should verify receipt proofs", async () => {
+        for (const n of [1, 2, 16, 130]) {
+            const receipts = Array.from({length: n}, (_, i) => randomReceipt(i));
+            const trie = new Trie(receipts.map((r, i) => [indexKey(i), r]));
+
+            for (const i of [0, 1, n >> 1, n - 1, 127, 128].filter(i => i < n)) {
+                const proof = trie.prove(indexKey(i));
+                assert(await mpt.verifyReceipt(trie.rootHash(), i, receipts[i], proof), `receipt ${i} of ${n}`);
+                assert.equal(await mpt.verifyReceipt(trie.rootHash(), i, receipts[(i + 1) % n] + '00', proof), false);
+            }
+        }
+    });
+
+    it("should verify the receipt proofs of the go prover", async () => {
+        for (const {receipts, root, proofs} of receiptProofs) {
+            assert.equal(new Trie(receipts.map((r, i) => [indexKey(i), r])).rootHash(), root);
+
+            for (const {index, proof} of proofs) {
+                assert(await mpt.verifyReceipt(root, index, receipts[index], proof), `receipt ${index} of ${receipts.length}`);
+                const other = receipts[(index + 1) % receipts.length];
+                if (other !== receipts[index]) assert.isFalse(await mpt.verifyReceipt(root, index, other, proof));
+            }
+        }
+    });
+
+    it("should verify transaction proofs", async () => {
+        // legacy and typed transactions, the trie holds them as they are hashed
+        const txs = Array.from({length: 40}, (_, i) => {
+            const tx = ethers.utils.RLP.encode([bls254.randHex(2), bls254.randHex(5), '0x5208', bls254.randHex(20), bls254.randHex(8), bls254.randHex(i * 4)]);
+            return i % 3 === 0 ? tx : ethers.utils.hexConcat(['0x02', tx]);
+        });
+        const trie = new Trie(txs.map((tx, i) => [indexKey(i), tx]));
+
+        for (const i of [0, 1, 15, 16, 39]) {
+            const proof = trie.prove(indexKey(i));
+            assert(await mpt.verifyTransaction(trie.rootHash(), i, txs[i], proof), `transaction ${i}`);
+            assert.isFalse(await mpt.verifyTransaction(trie.rootHash(), i, txs[(i + 1) % txs.length], proof));
+        }
+        assert.isFalse(await mpt.verifyTransaction(trie.rootHash(), 40, txs[0], trie.prove(indexKey(40))));
+    });
+
+    it("should verify embedded nodes", async () => {
+        const entries = Array.from({length: 20}, (_, i) => [indexKey(i), ethers.utils.hexlify(i + 1)]);
+        const trie = new Trie(entries);
+
+        for (const [key, value] of entries) {
+            const res = await mpt.get(trie.rootHash(), key, trie.prove(key));
+            assert(res.found);
+            assert.equal(res.value, value);
+        }
+    });
+
+    it("should prove absence", async () => {
+        const receipts = Array.from({length: 20}, (_, i) => randomReceipt(i));
+        const trie = new Trie(receipts.map((r, i) => [indexKey(i), r]));
+
+        for (const i of [20, 21, 128, 1000]) {
+            const res = await mpt.get(trie.rootHash(), indexKey(i), trie.prove(indexKey(i)));
+            assert.equal(res.found, false);
+        }
+    });
+
+    it("should reject bad proofs", async () => {
+        const receipts = Array.from({length: 20}, (_, i) => randomReceipt(i));
+        const trie = new Trie(receipts.map((r, i) => [indexKey(i), r]));
+        const proof = trie.prove(indexKey(5));
+
+        await assertRevert(mpt.verifyReceipt(bls254.randHex(32), 5, receipts[5], proof), 'bad trie proof');
+        await assertRevert(mpt.verifyReceipt(trie.rootHash(), 5, receipts[5], proof.slice(0, -1)), 'trie proof too short');
+
+        const tampered = [...proof];
+        tampered[tampered.length - 1] = trie.prove(indexKey(6)).pop();
+        await assertRevert(mpt.verifyReceipt(trie.rootHash(), 5, receipts[5], tampered), 'bad trie proof');
+    });
+
+    it("should verify receipt batches with shared proof nodes", async () => {
+        const receipts = Array.from({length: 130}, (_, i) => randomReceipt(i));
+        const trie = new Trie(receipts.map((r, i) => [indexKey(i), r]));
+        const indices = [0, 1, 2, 17, 64, 127, 128, 129];
+        const proofs = indices.map(i => trie.prove(indexKey(i)));
+
+        const bundle = packProofs(proofs);
+        const total = proofs.reduce((n, p) => n + p.length, 0);
+        assert(bundle.nodes.length < total, `${bundle.nodes.length} of ${total} nodes`);
+
+        const batch = indices.map(i => receipts[i]);
+        assert(await mpt.verifyReceipts(trie.rootHash(), indices, batch, bundle));
+
+        const swapped = [...batch];
+        [swapped[0], swapped[1]] = [swapped[1], swapped[0]];
+        assert.isFalse(await mpt.verifyReceipts(trie.rootHash(), indices, swapped, bundle));
+
+        await assertRevert(mpt.verifyReceipts(trie.rootHash(), indices.slice(1), batch, bundle), 'mismatch receipt batch');
+        const broken = {nodes: bundle.nodes, proofs: [[bundle.nodes.length], ...bundle.proofs.slice(1)]};
+        await assertRevert(mpt.verifyReceipts(trie.rootHash(), indices, batch, broken), 'bad proof node index');
+    });
+});
@@ -0,0 +1,154 @@
+const hre = require('hardhat');
+const {assert} = require('chai');
+const {ethers} = require('hardhat');
+const {BigNumber} = require("ethers");
+const {seededRandom} = require('../scripts/gen-vectors');
+const {canonical, isBytes, isUint, isExtra, rlpUint, encodeHeader, randomHeader, encodeExtra} = require('./header');
+
+const RLP = ethers.utils.RLP;
+const ROUNDS = 64;
+
+const num = (v) => BigNumber.from(v === '0x' ? 0 : v);
+// differential fuzzing of the rlp decoders against the ethers one: mutations of valid encodings
+// go to both. whatever a contract accepts must decode with ethers to the same values, and
+// whatever is canonical rlp of the right shape must be accepted. ethers takes non-canonical
+// rlp as well, so that is left out of the second direction. the inputs and their mutations
+// are seeded, every run checks the same inputs.
+describe('RLP fuzzing', function () {
+    const {randHex} = seededRandom('rlp fuzz');
+    const randInt = (n) => BigNumber.from(randHex(4)).toNumber() % n;
+
+    let headerCodec;
+    let blockCodec;
+    let extraCodec;
+
+    function mutate(hex) {
+        const bytes = Array.from(ethers.utils.arrayify(hex));
+        const at = randInt(bytes.length);
+        switch (randInt(5)) {
+            case 0: bytes[at] = randInt(256); break;
+            case 1: bytes[at] = (bytes[at] + (randInt(2) ? 1 : 255)) % 256; break;
+            case 2: bytes.splice(at, 1); break;
+            case 3: bytes.splice(at, 0, randInt(256)); break;
+            default: bytes.length = at; break;
+        }
+        return ethers.utils.hexlify(bytes);
+    }
+
+    // the value of the call, or undefined when it reverts
+    async function accepted(call) {
+        try {
+            return await call;
+        } catch (e) {
+            return undefined;
+        }
+    }
+
+    function decodeList(hex) {
+        const list = RLP.decode(hex);
+        assert(Array.isArray(list), 'accepted a string where a list is expected');
+        return list;
+    }
+
+    // wellFormed tells whether the contract has to take an input
+    async function fuzz(valid, decode, check, wellFormed) {
+        let hits = 0;
+        for (let i = 0; i < ROUNDS; i++) {
+            const input = mutate(valid[i % valid.length]);
+            const res = await accepted(decode(input));
+            if (res !== undefined) {
+                check(res, input);
+                hits++;
+            } else {
+                assert(!wellFormed(input), 'rejected ' + input);
+            }
+        }
+        // the mutations must not all be rejected, or nothing was compared
+        assert(hits > 0, 'no mutation was accepted');
+    }
+
+    before(async () => {
+        const TestHeaderCodec = await hre.ethers.getContractFactory('TestHeaderCodec');
+        headerCodec = await TestHeaderCodec.deploy();
+        await headerCodec.deployed();
+
+        const TestBlockCodec = await hre.ethers.getContractFactory('TestBlockCodec');
+        blockCodec = await TestBlockCodec.deploy();
+        await blockCodec.deployed();
+
+        const TestIstanbulExtra = await hre.ethers.getContractFactory('TestIstanbulExtra');
+        extraCodec = await TestIstanbulExtra.deploy();
+        await extraCodec.deployed();
+    });
+
+    it("should decode headers like ethers", async () => {
+        const blob = {...randomHeader(true, encodeExtra(), randHex), hasBlobGas: true, blobGasUsed: BigNumber.from(randHex(3)), excessBlobGas: BigNumber.from(0)};
+        const valid = [
+            randomHeader(false, encodeExtra(), randHex),
+            randomHeader(true, encodeExtra(), randHex),
+            {...blob, hasParentBeaconRoot: true, parentBeaconRoot: randHex(32)},
+        ].map(h => encodeHeader(h));
+
+        // HeaderCodec.decode: fixed width hashes, uint64 gas and time
+        const sizes = {0: 32, 1: 20, 2: 32, 3: 32, 4: 32, 5: 256, 11: 32, 12: 8, 16: 32};
+        const uints = {6: 32, 7: 8, 8: 8, 9: 8, 13: 32, 14: 8, 15: 8};
+        const isHeader = (f) => Array.isArray(f) && f.length >= 13 && f.length <= 17 && f.length !== 15 &&
+            f.every((v, i) => i in uints ? isUint(v, uints[i]) : isBytes(v, sizes[i]));
+
+        await fuzz(valid, (input) => headerCodec.decode(input), (h, input) => {
+            const f = decodeList(input);
+            assert.deepEqual([h.parentHash, h.root, h.txHash, h.receiptHash, h.mixDigest], [f[0], f[2], f[3], f[4], f[11]], input);
+            assert.equal(h.coinbase.toLowerCase(), f[1]);
+            assert.equal(h.bloom, f[5]);
+            assert(h.number.eq(num(f[6])) && h.gasLimit.eq(num(f[7])) && h.gasUsed.eq(num(f[8])) && h.time.eq(num(f[9])), input);
+            assert.equal(h.extra, f[10]);
+            assert.equal(h.nonce, f[12]);
+            assert.equal(h.hasBaseFee, f.length > 13);
+            if (h.hasBaseFee) assert(h.baseFee.eq(num(f[13])));
+            assert.equal(h.hasBlobGas, f.length > 14);
+            if (h.hasBlobGas) assert(h.blobGasUsed.eq(num(f[14])) && h.excessBlobGas.eq(num(f[15])), input);
+            assert.equal(h.hasParentBeaconRoot, f.length > 16);
+            if (h.hasParentBeaconRoot) assert.equal(h.parentBeaconRoot, f[16]);
+        }, (input) => canonical(input) && isHeader(RLP.decode(input)));
+    });
+
+    it("should decode epoch snark data like ethers", async () => {
+        const header = RLP.decode(encodeHeader(randomHeader(true, encodeExtra(), randHex)));
+        const valid = [
+            RLP.encode([header, [], [], [rlpUint(randHex(2)), randHex(64)]]),
+            RLP.encode([header, [], [randHex(32), randHex(32)], ['0x', '0x']]),
+        ];
+
+        await fuzz(valid, (input) => blockCodec.epochSnarkData(input), (e, input) => {
+            const snark = decodeList(input)[3];
+            // an empty list is a nil EpochSnarkData, which go does not decode either
+            assert(Array.isArray(snark) && snark.length === 2, input);
+            assert(e.bitmap.eq(num(snark[0])), input);
+            assert.equal(e.signature, snark[1]);
+        }, (input) => {
+            if (!canonical(input)) return false;
+            // only the list of the block and its snark data are decoded
+            const block = RLP.decode(input);
+            const snark = block[3];
+            return Array.isArray(block) && block.length === 4 && Array.isArray(snark) && snark.length === 2 && isUint(snark[0]) && isBytes(snark[1]);
+        });
+    });
+
+    it("should decode istanbul extra like ethers", async () => {
+        const seal = {bitmap: 7, signature: randHex(64), round: 1};
+        const valid = [
+            encodeExtra({aggregatedSeal: seal, seal: randHex(65)}),
+            encodeExtra({addedValidators: [randHex(20)], addedPubKeys: [randHex(128)], addedG1PubKeys: [randHex(64)], removedValidators: 5}),
+        ];
+
+        await fuzz(valid, (input) => extraCodec.decode(input), (ist, input) => {
+            const f = decodeList(ethers.utils.hexDataSlice(input, 32));
+            assert.deepEqual(ist.addedValidators.map(a => a.toLowerCase()), f[0]);
+            assert.deepEqual(ist.addedPubKeys, f[1]);
+            assert(ist.removedValidators.eq(num(f[3])), input);
+            assert.equal(ist.seal, f[4]);
+            assert(ist.aggregatedSeal.bitmap.eq(num(f[5][0])) && ist.aggregatedSeal.round.eq(num(f[5][2])), input);
+            assert.equal(ist.aggregatedSeal.signature, f[5][1]);
+        }, isExtra);
+    });
+});
@@ -0,0 +1,180 @@
+const hre = require('hardhat');
+const {assert} = require('chai');
+const {ethers} = require('hardhat');
+const bls254 = require('./blsbn254');
+const {BigNumber} = require("ethers");
+const {SEAL_DST, chainConfig, encodeHeader, encodeCompactHeader, headerHash, randomHeader, encodeExtra, committedSealMessage} = require('./header');
+const {assertRevert, convertG2, lightNodeFactory} = require('./helpers');
+
+const EPOCH_SIZE = 4;
+const STAKE = ethers.utils.parseEther('1');
+
+describe('RelayerRegistry', function () {
+    let node;
+    let registry;
+    let keys;
+    let genesisHash;
+    let owner;
+    let honest;
+    let malicious;
+
+    // header number on top of parent, sealed by signingKeys[i] for every i in signers
+    function sealHeader(parentHash, number, signers, signingKeys = keys) {
+        const h = randomHeader(true, encodeExtra());
+        h.parentHash = parentHash;
+        h.number = BigNumber.from(number);
+
+        const hash = headerHash(h);
+        const message = committedSealMessage(hash, 0);
+        let bitmap = BigNumber.from(0);
+        let sig;
+        signers.forEach(i => {
+            const s = bls254.signWithDST(SEAL_DST, message, signingKeys[i].secret).signature;
+            sig = sig ? bls254.aggreagate(sig, s) : s;
+            bitmap = bitmap.or(BigNumber.from(1).shl(i));
+        });
+
+        h.extra = encodeExtra({aggregatedSeal: {bitmap, signature: ethers.utils.hexConcat(bls254.g1ToHex(sig)), round: 0}});
+        return {rlp: encodeHeader(h), hash, header: h};
+    }
+
+    beforeEach(async () => {
+        await bls254.init();
+        [owner, honest, malicious] = await hre.ethers.getSigners();
+        keys = Array.from({length: 4}, () => bls254.newKeyPair());
+        genesisHash = bls254.randHex(32);
+
+        // the registry is deployed right after the light node that takes only its submissions
+        const nonce = await owner.getTransactionCount();
+        const registryAddress = ethers.utils.getContractAddress({from: owner.address, nonce: nonce + 1});
+        const LightNode = await lightNodeFactory();
+        node = await LightNode.deploy(chainConfig(EPOCH_SIZE), 0, genesisHash, keys.map(k => convertG2(k.pubkey)), registryAddress);
+        await node.deployed();
+
+        const RelayerRegistry = await hre.ethers.getContractFactory('RelayerRegistry');
+        registry = await RelayerRegistry.deploy(node.address, STAKE);
+        await registry.deployed();
+        assert.equal(await node.registry(), registry.address);
+
+        for (const relayer of [honest, malicious]) {
+            await registry.addRelayer(relayer.address);
+            await registry.connect(relayer).stake({value: STAKE});
+        }
+    });
+
+    it("should only accept staked relayers on the whitelist", async () => {
+        const h = sealHeader(genesisHash, 1, [0, 1, 2]);
+        const [, , , stranger] = await hre.ethers.getSigners();
+
+        await assertRevert(registry.connect(stranger).submitHeader(h.rlp), 'not a relayer');
+        await registry.addRelayer(stranger.address);
+        await assertRevert(registry.connect(stranger).submitHeader(h.rlp), 'not a relayer');
+        await assertRevert(registry.connect(honest).addRelayer(stranger.address), 'only owner');
+
+        await registry.connect(stranger).stake({value: STAKE});
+        await registry.connect(stranger).submitHeader(h.rlp);
+        assert.equal(await node.headHash(), h.hash);
+    });
+
+    it("should relay a header once", async () => {
+        const h = sealHeader(genesisHash, 1, [0, 1, 2]);
+        const tx = await registry.connect(honest).submitHeader(h.rlp);
+        const relayed = (await tx.wait()).events.find(e => e.event === 'HeaderRelayed');
+
+        assert.equal(relayed.args.relayer, honest.address);
+        assert.equal(await registry.submitters(ethers.utils.keccak256(h.rlp)), honest.address);
+        assert.equal(await node.whoProved(h.hash), honest.address);
+
+        await assertRevert(registry.connect(malicious).submitHeader(h.rlp), 'header already relayed');
+    });
+
+    it("should be the only way to submit to the light node", async () => {
+        const h = sealHeader(genesisHash, 1, [0, 1, 2]);
+        await assertRevert(node.connect(honest).submitHeader(h.rlp, honest.address), 'only registry');
+        await assertRevert(node.connect(honest).submitCompactHeader(encodeCompactHeader(h.header), honest.address), 'only registry');
+    });
+
+    it("should relay compact headers", async () => {
+        const h = sealHeader(genesisHash, 1, [0, 1, 2]);
+        const compact = encodeCompactHeader(h.header);
+        await registry.connect(honest).submitCompactHeader(compact);
+        assert.equal(await node.headHash(), h.hash);
+        assert.equal(await registry.submitters(ethers.utils.keccak256(compact)), honest.address);
+        assert.equal(await node.whoProved(h.hash), honest.address);
+        await assertRevert(registry.connect(honest).submitCompactHeader(compact), 'header already relayed');
+
+        // forged compact headers are slashed the same way
+        const forgers = Array.from({length: 4}, () => bls254.newKeyPair());
+        const forged = sealHeader(h.hash, 2, [0, 1, 2], forgers);
+        await registry.connect(malicious).submitCompactHeader(encodeCompactHeader(forged.header));
+        assert.isFalse(await registry.isRelayer(malicious.address));
+        assert.equal(await node.headHash(), h.hash);
+    });
+
+    it("should slash a relayer submitting a forged header", async () => {
+        // sealed by keys the light node does not know
+        const forgers = Array.from({length: 4}, () => bls254.newKeyPair());
+        const forged = sealHeader(genesisHash, 1, [0, 1, 2], forgers);
+
+        const tx = await registry.connect(malicious).submitHeader(forged.rlp);
+        const slashed = (await tx.wait()).events.find(e => e.event === 'Slashed');
+        assert.equal(slashed.args.relayer, malicious.address);
+        assert(slashed.args.amount.eq(STAKE));
+        assert.equal(slashed.args.status, 5); // InvalidSeal
+
+        assert.isFalse(await registry.isRelayer(malicious.address));
+        assert((await registry.slashed()).eq(STAKE));
+        assert.equal(await node.headHash(), genesisHash);
+        assert.equal(await registry.submitters(ethers.utils.keccak256(forged.rlp)), ethers.constants.AddressZero);
+
+        // too few signers is a forgery as well
+        const short = sealHeader(genesisHash, 1, [0, 1]);
+        const status = (await (await registry.connect(honest).submitHeader(short.rlp)).wait()).events.find(e => e.event === 'Slashed').args.status;
+        assert.equal(status, 4); // NotEnoughSigners
+        assert.isFalse(await registry.isRelayer(honest.address));
+    });
+
+    it("should slash a relayer submitting a seal that is not a point", async () => {
+        const h = sealHeader(genesisHash, 1, [0, 1, 2]);
+        const seal = ethers.utils.RLP.decode(ethers.utils.hexDataSlice(h.header.extra, 32))[5];
+        const y = BigNumber.from(ethers.utils.hexDataSlice(seal[1], 32)).add(1);
+        const signature = ethers.utils.hexConcat([ethers.utils.hexDataSlice(seal[1], 0, 32), bls254.bigToHex(y)]);
+        h.header.extra = encodeExtra({aggregatedSeal: {bitmap: seal[0], signature, round: 0}});
+
+        const tx = await registry.connect(malicious).submitHeader(encodeHeader(h.header));
+        const slashed = (await tx.wait()).events.find(e => e.event === 'Slashed');
+        assert.equal(slashed.args.relayer, malicious.address);
+        assert.equal(slashed.args.status, 5); // InvalidSeal
+        assert.equal(await node.headHash(), genesisHash);
+    });
+
+    it("should revert without slashing on other failures", async () => {
+        const h = sealHeader(genesisHash, 1, [0, 1, 2]);
+        await registry.connect(honest).submitHeader(h.rlp);
+
+        // a competing header for a height that is already taken
+        await assertRevert(registry.connect(malicious).submitHeader(sealHeader(genesisHash, 1, [0, 1, 2]).rlp), 'unexpected header number');
+        await assertRevert(registry.connect(malicious).submitHeader(sealHeader(bls254.randHex(32), 2, [0, 1, 2]).rlp), 'parent hash mismatch');
+        // reasons of the light node that are not about the seal come through as they are
+        await assertRevert(registry.connect(malicious).submitHeader(ethers.utils.RLP.encode(['0x01'])), 'bad header');
+        assert(await registry.isRelayer(malicious.address));
+        assert((await registry.slashed()).isZero());
+    });
+
+    it("should lock stake until the relayer is removed", async () => {
+        await assertRevert(registry.connect(honest).withdraw(STAKE), 'stake locked');
+
+        await registry.removeRelayer(honest.address);
+        await assertRevert(registry.connect(honest).withdraw(STAKE.add(1)), 'insufficient stake');
+        await registry.connect(honest).withdraw(STAKE);
+        assert((await registry.relayers(honest.address)).stake.isZero());
+
+        await registry.connect(malicious).submitHeader(sealHeader(genesisHash, 1, [0, 1]).rlp);
+        await assertRevert(registry.connect(honest).collectSlashed(honest.address), 'only owner');
+        // the light node takes no ether
+        await assertRevert(registry.collectSlashed(node.address), 'transfer failed');
+        const before = await hre.ethers.provider.getBalance(owner.address);
+        await registry.collectSlashed(owner.address, {gasPrice: 0});
+        assert((await hre.ethers.provider.getBalance(owner.address)).sub(before).eq(STAKE));
+    });
+});
@@ -0,0 +1,80 @@
+const hre = require('hardhat');
+const {assert} = require('chai');
+const bls254 = require('./blsbn254');
+const {generate} = require('../scripts/gen-vectors');
+const {chainConfig} = require('./header');
+const {lightNodeFactory} = require('./helpers');
+
+// written by scripts/gen-vectors.js, the go tests in testdata read it as well
+const vectors = require('./testdata/vectors.json');
+
+const equalG1 = (p, q) => p.x.eq(q.x) && p.y.eq(q.y);
+
+describe('gen-vectors', function () {
+    let bgls;
+    let codec;
+
+    before(async () => {
+        await bls254.init();
+
+        const BGLS = await hre.ethers.getContractFactory('BGLS');
+        bgls = await BGLS.deploy();
+        await bgls.deployed();
+
+        const TestHeaderCodec = await hre.ethers.getContractFactory('TestHeaderCodec');
+        codec = await TestHeaderCodec.deploy();
+        await codec.deployed();
+    });
+
+    it("should generate the committed vectors from their seed", async () => {
+        // fails when the generator or the helpers it uses drift from the file, run the script again
+        assert.deepEqual(generate(vectors.seed, vectors.count), vectors);
+        assert.notDeepEqual(generate('other', vectors.count).g1Mul, vectors.g1Mul);
+    });
+
+    it("should agree with the contracts on curve operations", async () => {
+        for (const v of vectors.g1Add) {
+            assert(equalG1(await bgls.callStatic.addPoints(v.a, v.b), v.sum));
+        }
+        for (const v of vectors.g1Mul) {
+            assert(equalG1(await bgls.callStatic.scalarMultiply(v.point, v.scalar), v.product));
+        }
+    });
+
+    it("should agree with the contracts on signatures", async () => {
+        for (const v of vectors.signatures) {
+            assert(await bgls.callStatic.checkSignature(v.message, v.signature, v.pubkey));
+            for (const s of v.signers) {
+                assert(await bgls.callStatic.checkSignature(v.message, s.signature, s.pubkey));
+            }
+        }
+    });
+
+    it("should agree with the contracts on header hashes", async () => {
+        for (const v of vectors.headers) {
+            const h = await codec.decode(v.rlp);
+            assert.equal(await codec.hash(h), v.hash);
+            assert.equal(await codec.hashHeaderForSeal(h), v.sigHash);
+            assert.equal(await codec.committedSealMessage(h, v.round), v.committedSealMessage);
+        }
+    });
+
+    it("should hash headers with a malformed istanbul extra as go does", async () => {
+        for (const v of vectors.invalidExtraHeaders) {
+            const h = await codec.decode(v.rlp);
+            assert.equal(await codec.hash(h), v.hash);
+            assert.equal(await codec.hashHeaderForSeal(h), v.sigHash);
+        }
+    });
+
+    it("should agree with the light node on aggregated seals", async () => {
+        // the seals are aggregated by AggregateEpochSnarkData in the go tests
+        const [registry] = await hre.ethers.getSigners();
+        const LightNode = await lightNodeFactory();
+        for (const v of vectors.aggregatedSeals) {
+            const node = await LightNode.deploy(chainConfig(4), 0, v.genesis, v.validators.map(s => s.pubkey), registry.address);
+            await node.deployed();
+            assert.equal(await node.callStatic.verifyHeader(v.rlp), 0);
+        }
+    });
+});
@@ -0,0 +1,154 @@
+const hre = require('hardhat');
+const {assert} = require('chai');
+const {ethers} = require('hardhat');
+const bls254 = require('./blsbn254');
+const {Trie, indexKey} = require('./mpt');
+const {rlpUint, encodeHeader, headerHash, randomHeader, encodeExtra} = require('./header');
+const {assertRevert} = require('./helpers');
+
+const RLP = ethers.utils.RLP;
+
+function receipt(status, logs, typed) {
+    const r = RLP.encode([rlpUint(status), bls254.randHex(3), bls254.randHex(256), logs]);
+    return typed ? ethers.utils.hexConcat(['0x02', r]) : r;
+}
+
+function randomLog() {
+    return [bls254.randHex(20), [bls254.randHex(32), bls254.randHex(32)], bls254.randHex(40)];
+}
+
+describe('VerifiedReads', function () {
+    let node;
+    let reads;
+
+    // a verified header committing to the given receipts, state and transactions
+    async function verifiedHeader(receipts, stateRoot, txs) {
+        const trie = new Trie(receipts.map((r, i) => [indexKey(i), r]));
+        const h = randomHeader(false, encodeExtra());
+        h.receiptHash = trie.rootHash();
+        if (stateRoot) h.root = stateRoot;
+        if (txs) h.txHash = new Trie(txs.map((tx, i) => [indexKey(i), tx])).rootHash();
+
+        await node.setHeader(h.number, headerHash(h));
+        return {rlp: encodeHeader(h), trie, header: h};
+    }
+
+    beforeEach(async () => {
+        const MockLightNode = await hre.ethers.getContractFactory('MockLightNode');
+        node = await MockLightNode.deploy();
+        await node.deployed();
+
+        const TestVerifiedReads = await hre.ethers.getContractFactory('TestVerifiedReads');
+        reads = await TestVerifiedReads.deploy(node.address);
+        await reads.deployed();
+    });
+
+    it("should require a successful transaction", async () => {
+        const receipts = [receipt(1, [], false), receipt(0, [], true), receipt(1, [randomLog()], true)];
+        const h = await verifiedHeader(receipts);
+
+        await reads.requireTxSucceeded([h.rlp], 0, receipts[0], h.trie.prove(indexKey(0)));
+        await reads.requireTxSucceeded([h.rlp], 2, receipts[2], h.trie.prove(indexKey(2)));
+        await assertRevert(reads.requireTxSucceeded([h.rlp], 1, receipts[1], h.trie.prove(indexKey(1))), 'tx failed');
+        await assertRevert(reads.requireTxSucceeded([h.rlp], 1, receipts[0], h.trie.prove(indexKey(1))), 'bad receipt proof');
+    });
+
+    it("should only read from verified headers", async () => {
+        const receipts = [receipt(1, [], false)];
+        const h = await verifiedHeader(receipts);
+
+        const other = {...h.header, gasUsed: h.header.gasUsed.add(1)};
+        await assertRevert(reads.requireTxSucceeded([encodeHeader(other)], 0, receipts[0], h.trie.prove(indexKey(0))), 'header segment not anchored');
+    });
+
+    it("should take the header hash from the light node", async () => {
+        const receipts = [receipt(1, [], false)];
+        const h = await verifiedHeader(receipts);
+
+        // a celo extra, which the light node hashes without its aggregated seal
+        const celo = ethers.utils.hexConcat([ethers.utils.hexZeroPad('0x', 32), RLP.encode([
+            [], [], '0x', '0x', ['0x07', bls254.randHex(64), '0x'], ['0x', '0x', '0x'],
+        ])]);
+        const rlp = encodeHeader({...h.header, extra: celo});
+        await node.setLayout({vanity: 32, hasG1PubKeys: false});
+        const hash = await node.hashOf(rlp);
+        assert.notEqual(hash, headerHash({...h.header, extra: celo}));
+
+        await node.setHeader(h.header.number, hash);
+        await reads.requireTxSucceeded([rlp], 0, receipts[0], h.trie.prove(indexKey(0)));
+    });
+
+    it("should read from pruned headers linked to a verified one", async () => {
+        const receipts = [receipt(1, [], false)];
+        const h = await verifiedHeader(receipts);
+        await node.setHeader(h.header.number, ethers.constants.HashZero);
+        const proof = h.trie.prove(indexKey(0));
+        await assertRevert(reads.requireTxSucceeded([h.rlp], 0, receipts[0], proof), 'header segment not anchored');
+
+        const child = {...randomHeader(false, encodeExtra()), parentHash: headerHash(h.header), number: h.header.number.add(1)};
+        const grandchild = {...randomHeader(false, encodeExtra()), parentHash: headerHash(child), number: child.number.add(1)};
+        await node.setHeader(grandchild.number, headerHash(grandchild));
+
+        await reads.requireTxSucceeded([h.rlp, encodeHeader(child), encodeHeader(grandchild)], 0, receipts[0], proof);
+        await assertRevert(reads.requireTxSucceeded([h.rlp, encodeHeader(child)], 0, receipts[0], proof), 'header segment not anchored');
+        await assertRevert(reads.requireTxSucceeded([h.rlp, encodeHeader(grandchild)], 0, receipts[0], proof), 'broken header segment');
+        await assertRevert(reads.requireTxSucceeded([], 0, receipts[0], proof), 'empty header segment');
+    });
+
+    it("should require an included transaction", async () => {
+        const txs = [RLP.encode([bls254.randHex(2), bls254.randHex(20), bls254.randHex(40)]), ethers.utils.hexConcat(['0x02', RLP.encode([bls254.randHex(32)])])];
+        const h = await verifiedHeader([receipt(1, [], false), receipt(1, [], true)], null, txs);
+        const trie = new Trie(txs.map((tx, i) => [indexKey(i), tx]));
+
+        for (const i of [0, 1]) {
+            assert.equal(await reads.requireTxIncluded([h.rlp], i, txs[i], trie.prove(indexKey(i))), ethers.utils.keccak256(txs[i]));
+        }
+        await assertRevert(reads.requireTxIncluded([h.rlp], 0, txs[1], trie.prove(indexKey(0))), 'bad transaction proof');
+        // the receipt trie is not the transaction trie
+        await assertRevert(reads.requireTxIncluded([h.rlp], 0, txs[0], h.trie.prove(indexKey(0))), 'bad trie proof');
+    });
+
+    it("should read each event once", async () => {
+        const logs = [randomLog(), randomLog()];
+        const receipts = [receipt(1, [], false), receipt(1, logs, true)];
+        const h = await verifiedHeader(receipts);
+        const proof = h.trie.prove(indexKey(1));
+
+        const tx = await reads.readEventOnce([h.rlp], 1, receipts[1], proof, 1);
+        const args = (await tx.wait()).events.find(e => e.event === 'EventRead').args;
+        assert.equal(args.emitter.toLowerCase(), logs[1][0]);
+        assert.deepEqual(args.topics, logs[1][1]);
+        assert.equal(args.data, logs[1][2]);
+
+        await assertRevert(reads.readEventOnce([h.rlp], 1, receipts[1], proof, 1), 'event already read');
+        await reads.readEventOnce([h.rlp], 1, receipts[1], proof, 0);
+        await assertRevert(reads.readEventOnce([h.rlp], 1, receipts[1], proof, 2), 'bad log index');
+    });
+
+    it("should read storage at a verified state root", async () => {
+        const account = ethers.utils.getAddress(bls254.randHex(20));
+        const slot = ethers.utils.hexZeroPad('0x05', 32);
+        const value = 0xdeadbeef;
+
+        const storage = new Trie([
+            [ethers.utils.keccak256(slot), RLP.encode(rlpUint(value))],
+            [ethers.utils.keccak256(ethers.utils.hexZeroPad('0x06', 32)), RLP.encode('0x01')],
+        ]);
+        const accountRlp = RLP.encode(['0x01', '0x', storage.rootHash(), ethers.utils.keccak256('0x')]);
+        const accountKey = ethers.utils.keccak256(account);
+        const state = new Trie([
+            [accountKey, accountRlp],
+            [ethers.utils.keccak256(bls254.randHex(20)), accountRlp],
+        ]);
+        const h = await verifiedHeader([receipt(1, [], false)], state.rootHash());
+
+        const res = await reads.readStorageAt([h.rlp], account, slot, state.prove(accountKey), storage.prove(ethers.utils.keccak256(slot)));
+        assert(res.eq(value));
+
+        // absent slot and absent account read as zero
+        const empty = ethers.utils.hexZeroPad('0x07', 32);
+        assert((await reads.readStorageAt([h.rlp], account, empty, state.prove(accountKey), storage.prove(ethers.utils.keccak256(empty)))).isZero());
+        const stranger = ethers.utils.getAddress(bls254.randHex(20));
+        assert((await reads.readStorageAt([h.rlp], stranger, slot, state.prove(ethers.utils.keccak256(stranger)), [])).isZero());
+    });
+});
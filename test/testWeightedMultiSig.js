@@ -2,26 +2,11 @@ const hre = require('hardhat');
 const {assert} = require('chai');
 const {ethers} = require('hardhat');
 const bls254 = require('./blsbn254');
-const {BigNumber} = require("ethers");
+const {assertRevert, convertG1, convertG2} = require('./helpers');
 
 const formatG1 = (p) => p.x.toHexString() + ',' + p.y.toHexString();
 const equalG1 = (p, q) => p.x.eq(q.x) && p.y.eq(q.y);
 
-function convertG1(mclG1) {
-    const hex = bls254.g1ToHex(mclG1);
-    return {x: BigNumber.from(hex[0]), y: BigNumber.from(hex[1])};
-}
-
-function convertG2(mclG2) {
-    const hex = bls254.g2ToHex(mclG2);
-    return {
-        xr: BigNumber.from(hex[0]),
-        xi: BigNumber.from(hex[1]),
-        yr: BigNumber.from(hex[2]),
-        yi: BigNumber.from(hex[3]),
-    };
-}
-
 describe('WeightedMultiSig', function () {
     let wms;
 
@@ -57,7 +42,6 @@ describe('WeightedMultiSig', function () {
         await wms.deployed();
     });
 
-
     it("should verify maximum quorum", async () => {
         assert(await wms.callStatic.isQuorum('0x0f')); // 1111
     });
@@ -92,4 +76,50 @@ describe('WeightedMultiSig', function () {
 
         assert(await wms.callStatic.checkSig(bits, message, convertG1(aggSig), convertG2(aggPkG2)));
     });
+
+    it("should reject bits of wrong length", async () => {
+        await assertRevert(wms.callStatic.isQuorum('0x'), 'bad bits length');
+        await assertRevert(wms.callStatic.isQuorum('0x0f00'), 'bad bits length');
+
+        const aggPkG2 = bls254.aggreagate(signers[0].pkG2, signers[1].pkG2);
+        await assertRevert(wms.callStatic.checkAggPk('0x0300', convertG2(aggPkG2)), 'bad bits length');
+    });
+
+    it("should reject validator set over the cap", async () => {
+        const max = (await wms.MAX_VALIDATORS()).toNumber();
+        const keys = Array(max + 1).fill(convertG1(signers[0].pkG1));
+        const ws = Array(max + 1).fill(1);
+
+        const WeightedMultiSig = await hre.ethers.getContractFactory('WeightedMultiSig');
+        await assertRevert(WeightedMultiSig.deploy(threshold, keys, ws), 'too many validators');
+    });
+
+    it("should reject weight over the cap", async () => {
+        const max = await wms.MAX_WEIGHT();
+        const keys = signers.map(s => convertG1(s.pkG1));
+
+        const WeightedMultiSig = await hre.ethers.getContractFactory('WeightedMultiSig');
+        await assertRevert(WeightedMultiSig.deploy(threshold, keys, [1, 1, 1, max.add(1)]), 'weight too large');
+    });
+
+    it("should sum maximum weights without overflow", async () => {
+        const max = await wms.MAX_WEIGHT();
+        const keys = signers.map(s => convertG1(s.pkG1));
+
+        const WeightedMultiSig = await hre.ethers.getContractFactory('WeightedMultiSig');
+        const heavy = await WeightedMultiSig.deploy(max.mul(3), keys, Array(num).fill(max));
+        await heavy.deployed();
+
+        assert(await heavy.callStatic.isQuorum('0x0f'));
+        assert(await heavy.callStatic.isQuorum('0x07'));
+        assert.equal(await heavy.callStatic.isQuorum('0x03'), false);
+    });
+
+    it("should reject keys off the curve", async () => {
+        const keys = signers.map(s => convertG1(s.pkG1));
+        keys[2] = {x: keys[2].x, y: keys[2].y.add(1)};
+
+        const WeightedMultiSig = await hre.ethers.getContractFactory('WeightedMultiSig');
+        await assertRevert(WeightedMultiSig.deploy(threshold, keys, Array(num).fill(1)), 'invalid G1 point');
+    });
 });
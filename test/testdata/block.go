@@ -15,6 +15,11 @@
 // along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
 
 // Package types contains data types related to Ethereum consensus.
+//
+// In this repository it is the copy of the atlas types that the contracts are checked
+// against. It builds, and its tests run, inside the atlas module, which provides the
+// dependencies. The repository has no Go module of its own, so the tools that run from
+// it, such as the test vector generator, are node scripts under scripts/.
 package types
 
 import (
@@ -88,16 +93,28 @@ type Header struct {
 
 	// BaseFee was added by EIP-1559 and is ignored in legacy headers.
 	BaseFee *big.Int `json:"baseFeePerGas" rlp:"optional"`
+
+	// BlobGasUsed was added by EIP-4844 and is ignored in legacy headers.
+	BlobGasUsed *uint64 `json:"blobGasUsed" rlp:"optional"`
+
+	// ExcessBlobGas was added by EIP-4844 and is ignored in legacy headers.
+	ExcessBlobGas *uint64 `json:"excessBlobGas" rlp:"optional"`
+
+	// ParentBeaconRoot was added by EIP-4788 and is ignored in legacy headers.
+	ParentBeaconRoot *common.Hash `json:"parentBeaconBlockRoot" rlp:"optional"`
 }
 
 // field type overrides for gencodec
 type headerMarshaling struct {
-	Number   *hexutil.Big
-	GasLimit hexutil.Uint64
-	GasUsed  hexutil.Uint64
-	Time     hexutil.Uint64
-	Extra    hexutil.Bytes
-	Hash     common.Hash `json:"hash"` // adds call to Hash() in MarshalJSON
+	Number        *hexutil.Big
+	GasLimit      hexutil.Uint64
+	GasUsed       hexutil.Uint64
+	Time          hexutil.Uint64
+	Extra         hexutil.Bytes
+	BaseFee       *hexutil.Big
+	BlobGasUsed   *hexutil.Uint64
+	ExcessBlobGas *hexutil.Uint64
+	Hash          common.Hash `json:"hash"` // adds call to Hash() in MarshalJSON
 }
 
 // Hash returns the block hash of the header, which is simply the keccak256 hash of its
@@ -136,9 +153,89 @@ func (h *Header) SanityCheck() error {
 			return fmt.Errorf("too large base fee: bitlen %d", bfLen)
 		}
 	}
+	// optional fields can only be left out from the end, and the blob gas fields come in pairs
+	if (h.BlobGasUsed == nil) != (h.ExcessBlobGas == nil) {
+		return fmt.Errorf("incomplete blob gas fields")
+	}
+	if h.BlobGasUsed != nil && h.BaseFee == nil {
+		return fmt.Errorf("blob gas fields without base fee")
+	}
+	if h.ParentBeaconRoot != nil && h.BlobGasUsed == nil {
+		return fmt.Errorf("parent beacon root without blob gas fields")
+	}
 	return nil
 }
 
+// Flags of the compact header encoding, see contracts/CompactHeader.sol.
+const (
+	compactBaseFee = 1 << iota
+	compactBlobGas
+	compactParentBeaconRoot
+	compactEmptyBloom
+)
+
+// MarshalCompact returns the fixed layout encoding of h that CompactHeader decodes and
+// LightNode.submitCompactHeader takes, which costs less calldata than the rlp.
+func (h *Header) MarshalCompact() ([]byte, error) {
+	if h.Number == nil {
+		return nil, fmt.Errorf("missing block number")
+	}
+	// the number takes 8 bytes, as in the rlp of a valid header
+	if !h.Number.IsUint64() {
+		return nil, fmt.Errorf("too large block number: bitlen %d", h.Number.BitLen())
+	}
+	if err := h.SanityCheck(); err != nil {
+		return nil, err
+	}
+
+	var flags byte
+	if h.BaseFee != nil {
+		flags |= compactBaseFee
+	}
+	if h.BlobGasUsed != nil {
+		flags |= compactBlobGas
+	}
+	if h.ParentBeaconRoot != nil {
+		flags |= compactParentBeaconRoot
+	}
+	if h.Bloom == (Bloom{}) {
+		flags |= compactEmptyBloom
+	}
+
+	enc := []byte{flags}
+	enc = append(enc, h.ParentHash[:]...)
+	enc = append(enc, h.Coinbase[:]...)
+	enc = append(enc, h.Root[:]...)
+	enc = append(enc, h.TxHash[:]...)
+	enc = append(enc, h.ReceiptHash[:]...)
+	if flags&compactEmptyBloom == 0 {
+		enc = append(enc, h.Bloom[:]...)
+	}
+	enc = appendUint64(enc, h.Number.Uint64())
+	enc = appendUint64(enc, h.GasLimit)
+	enc = appendUint64(enc, h.GasUsed)
+	enc = appendUint64(enc, h.Time)
+	enc = append(enc, h.MixDigest[:]...)
+	enc = append(enc, h.Nonce[:]...)
+	if h.BaseFee != nil {
+		enc = append(enc, common.LeftPadBytes(h.BaseFee.Bytes(), 32)...)
+	}
+	if h.BlobGasUsed != nil {
+		enc = appendUint64(enc, *h.BlobGasUsed)
+		enc = appendUint64(enc, *h.ExcessBlobGas)
+	}
+	if h.ParentBeaconRoot != nil {
+		enc = append(enc, h.ParentBeaconRoot[:]...)
+	}
+	return append(enc, h.Extra...), nil
+}
+
+func appendUint64(b []byte, v uint64) []byte {
+	var buf [8]byte
+	binary.BigEndian.PutUint64(buf[:], v)
+	return append(b, buf[:]...)
+}
+
 // EmptyBody returns true if there is no additional 'body' to complete the header
 // that is: no transactions.
 func (h *Header) EmptyBody() bool {
@@ -238,6 +335,18 @@ func CopyHeader(h *Header) *Header {
 	if h.BaseFee != nil {
 		cpy.BaseFee = new(big.Int).Set(h.BaseFee)
 	}
+	if h.BlobGasUsed != nil {
+		cpy.BlobGasUsed = new(uint64)
+		*cpy.BlobGasUsed = *h.BlobGasUsed
+	}
+	if h.ExcessBlobGas != nil {
+		cpy.ExcessBlobGas = new(uint64)
+		*cpy.ExcessBlobGas = *h.ExcessBlobGas
+	}
+	if h.ParentBeaconRoot != nil {
+		cpy.ParentBeaconRoot = new(common.Hash)
+		*cpy.ParentBeaconRoot = *h.ParentBeaconRoot
+	}
 	if len(h.Extra) > 0 {
 		cpy.Extra = make([]byte, len(h.Extra))
 		copy(cpy.Extra, h.Extra)
@@ -338,11 +447,11 @@ func (c *writeCounter) Write(b []byte) (int, error) {
 // WithSeal returns a new block with the data from b but the header replaced with
 // the sealed one.
 func (b *Block) WithSeal(header *Header) *Block {
-	cpy := *header
-
 	return &Block{
-		header:       &cpy,
-		transactions: b.transactions,
+		header:         CopyHeader(header),
+		transactions:   b.transactions,
+		randomness:     b.randomness,
+		epochSnarkData: b.epochSnarkData,
 	}
 }
 
@@ -436,13 +545,76 @@ func (r *EpochSnarkData) IsEmpty() bool {
 	return len(r.Signature) == 0
 }
 
+// AggregateEpochSnarkData sums the partial signatures sigs[i] of the validators at
+// indices[i] out of total, and sets bit indices[i] of the bitmap for each. Bit i is
+// validator i, the order LightNode.verifySeal and BLSVerify read the bitmap in.
+// Signatures are in the helper/bls encoding, the 64 byte x || y of the bn256 precompiles.
+func AggregateEpochSnarkData(sigs [][]byte, indices []int, total int) (*EpochSnarkData, error) {
+	if len(sigs) != len(indices) {
+		return nil, fmt.Errorf("%d signatures for %d signers", len(sigs), len(indices))
+	}
+	if len(sigs) == 0 {
+		return nil, fmt.Errorf("no signatures to aggregate")
+	}
+
+	bitmap := new(big.Int)
+	for i, index := range indices {
+		if index < 0 || index >= total {
+			return nil, fmt.Errorf("signer index %d out of range [0, %d)", index, total)
+		}
+		if bitmap.Bit(index) == 1 {
+			return nil, fmt.Errorf("duplicate signer index %d", index)
+		}
+		bitmap.SetBit(bitmap, index, 1)
+		if len(sigs[i]) != bls.SIGNATUREBYTES {
+			return nil, fmt.Errorf("signature %d: %d bytes, want %d", i, len(sigs[i]), bls.SIGNATUREBYTES)
+		}
+	}
+	signature, err := bls.AggregateSignatures(sigs)
+	if err != nil {
+		return nil, err
+	}
+	return &EpochSnarkData{Bitmap: bitmap, Signature: signature}, nil
+}
+
+// BitmapWords splits a signer bitmap into the uint256 words Bitmap.sol reads, word i
+// holding bits 256*i to 256*i+255. It always returns at least one word.
+func BitmapWords(bitmap *big.Int) []*big.Int {
+	n := (bitmap.BitLen() + 255) / 256
+	if n == 0 {
+		n = 1
+	}
+	mask := new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 256), big.NewInt(1))
+	words := make([]*big.Int, n)
+	for i := range words {
+		words[i] = new(big.Int).And(new(big.Int).Rsh(bitmap, uint(256*i)), mask)
+	}
+	return words
+}
+
+// BitmapFromWords is the inverse of BitmapWords.
+func BitmapFromWords(words []*big.Int) *big.Int {
+	bitmap := new(big.Int)
+	for i := len(words) - 1; i >= 0; i-- {
+		bitmap.Lsh(bitmap, 256)
+		bitmap.Or(bitmap, words[i])
+	}
+	return bitmap
+}
+
+// BitmapWords returns the bitmap of r in the representation of Bitmap.sol.
+func (r *EpochSnarkData) BitmapWords() []*big.Int {
+	if r.Bitmap == nil {
+		return BitmapWords(new(big.Int))
+	}
+	return BitmapWords(r.Bitmap)
+}
+
 // WithHeader returns a new block with the data from b but the header replaced with
 // the sealed one.
 func (b *Block) WithHeader(header *Header) *Block {
-	cpy := *header
-
 	return &Block{
-		header:         &cpy,
+		header:         CopyHeader(header),
 		transactions:   b.transactions,
 		randomness:     b.randomness,
 		epochSnarkData: b.epochSnarkData,
@@ -0,0 +1,88 @@
+package types
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func testBlock() *Block {
+	header := &Header{
+		ParentHash: common.HexToHash("0x01"),
+		Number:     big.NewInt(100),
+		GasLimit:   30000000,
+		Time:       1700000000,
+		Extra:      bytes.Repeat([]byte{0xab}, 512),
+		BaseFee:    big.NewInt(1000000000),
+	}
+	randomness := &Randomness{Revealed: common.HexToHash("0x02"), Committed: common.HexToHash("0x03")}
+	snark := &EpochSnarkData{Bitmap: big.NewInt(0x2f), Signature: bytes.Repeat([]byte{0xcd}, 64)}
+	return NewBlockWithHeader(header).WithBody(nil, randomness, snark)
+}
+
+func TestWithSeal(t *testing.T) {
+	for name, derive := range map[string]func(*Block, *Header) *Block{
+		"WithSeal":   (*Block).WithSeal,
+		"WithHeader": (*Block).WithHeader,
+	} {
+		b := testBlock()
+		hash := b.Hash() // cached on b
+
+		sealed := b.Header()
+		sealed.Extra = append(sealed.Extra, 0xef)
+		derived := derive(b, sealed)
+
+		if *derived.Randomness() != *b.Randomness() {
+			t.Errorf("%s: randomness %v, want %v", name, derived.Randomness(), b.Randomness())
+		}
+		if s := derived.EpochSnarkData(); s.Bitmap.Cmp(b.EpochSnarkData().Bitmap) != 0 || !bytes.Equal(s.Signature, b.EpochSnarkData().Signature) {
+			t.Errorf("%s: epoch snark data %v, want %v", name, s, b.EpochSnarkData())
+		}
+		if derived.BaseFee().Cmp(b.BaseFee()) != 0 {
+			t.Errorf("%s: base fee %v, want %v", name, derived.BaseFee(), b.BaseFee())
+		}
+
+		// the derived block has a copy of the header it was given, and not b's hash
+		if derived.MutableHeader() == sealed || derived.MutableHeader() == b.MutableHeader() {
+			t.Errorf("%s: header pointer is shared", name)
+		}
+		sealed.Extra[0] = 0
+		sealed.BaseFee.SetInt64(1)
+		if !bytes.Equal(derived.Extra(), append(b.Extra(), 0xef)) || derived.BaseFee().Cmp(b.BaseFee()) != 0 {
+			t.Errorf("%s: changing the sealed header changed the block", name)
+		}
+		if derived.Hash() == hash {
+			t.Errorf("%s: derived block has the hash of the original", name)
+		}
+		if b.Hash() != hash {
+			t.Errorf("%s: original block hash changed", name)
+		}
+	}
+}
+
+func TestMarshalCompact(t *testing.T) {
+	h := testBlock().Header()
+	empty, err := h.MarshalCompact()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if empty[0]&compactEmptyBloom == 0 {
+		t.Errorf("empty bloom not flagged")
+	}
+
+	h.Bloom[0] = 1
+	full, err := h.MarshalCompact()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if full[0]&compactEmptyBloom != 0 || len(full) != len(empty)+BloomByteLength {
+		t.Errorf("bloom not encoded")
+	}
+
+	h.Number = new(big.Int).Lsh(big.NewInt(1), 64)
+	if _, err := h.MarshalCompact(); err == nil {
+		t.Errorf("number past uint64 accepted")
+	}
+}
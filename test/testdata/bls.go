@@ -0,0 +1,107 @@
+package types
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	bn256 "github.com/ethereum/go-ethereum/crypto/bn256/cloudflare"
+)
+
+// The encodings of helper/bls, which atlas inherits from celo, and which BLSCodec reads:
+// a signature is the 64 byte G1 point x || y, a public key the 128 byte G2 point
+// xi || xr || yi || yr followed by a flag byte. bn256 marshals points in the same order,
+// so only the flag byte is added or dropped here.
+const (
+	SignatureLength = 64
+	PublicKeyLength = 129
+)
+
+func MarshalSignature(sig *bn256.G1) []byte {
+	return sig.Marshal()
+}
+
+func UnmarshalSignature(data []byte) (*bn256.G1, error) {
+	if len(data) != SignatureLength {
+		return nil, fmt.Errorf("signature of %d bytes, want %d", len(data), SignatureLength)
+	}
+	sig := new(bn256.G1)
+	if _, err := sig.Unmarshal(data); err != nil {
+		return nil, err
+	}
+	return sig, nil
+}
+
+// MarshalPublicKey writes the flag byte as zero.
+func MarshalPublicKey(key *bn256.G2) []byte {
+	return append(key.Marshal(), 0)
+}
+
+// UnmarshalPublicKey accepts keys with or without the flag byte, which must be zero.
+func UnmarshalPublicKey(data []byte) (*bn256.G2, error) {
+	if len(data) != PublicKeyLength && len(data) != PublicKeyLength-1 {
+		return nil, fmt.Errorf("public key of %d bytes, want %d", len(data), PublicKeyLength)
+	}
+	if len(data) == PublicKeyLength && data[PublicKeyLength-1] != 0 {
+		return nil, fmt.Errorf("public key flag %#x", data[PublicKeyLength-1])
+	}
+	key := new(bn256.G2)
+	if _, err := key.Unmarshal(data[:PublicKeyLength-1]); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+var (
+	fieldModulus, _ = new(big.Int).SetString("30644e72e131a029b85045b68181585d97816a916871ca8d3c208c16d87cfd47", 16)
+	legendreExp     = new(big.Int).Rsh(new(big.Int).Sub(fieldModulus, big.NewInt(1)), 1)
+	sqrtExp         = new(big.Int).Rsh(new(big.Int).Add(fieldModulus, big.NewInt(1)), 2)
+)
+
+// DomainMessage is len(dst) || dst || message, as BGLS.domainMessage builds it.
+func DomainMessage(dst, message []byte) ([]byte, error) {
+	if len(dst) == 0 || len(dst) > 255 {
+		return nil, fmt.Errorf("domain separation tag of %d bytes", len(dst))
+	}
+	return append(append([]byte{byte(len(dst))}, dst...), message...), nil
+}
+
+// HashToG1TryAndIncrement matches BGLS.hashToG1TryAndIncrement: x = keccak256(message ||
+// counter) mod p for the first counter that puts x on the curve, and the root y whose
+// parity is the top bit of the hash.
+func HashToG1TryAndIncrement(message []byte) (*bn256.G1, error) {
+	for counter := int64(0); counter < 256; counter++ {
+		h := new(big.Int).SetBytes(crypto.Keccak256(message, common.BigToHash(big.NewInt(counter)).Bytes()))
+		x := new(big.Int).Mod(h, fieldModulus)
+		px := new(big.Int).Mul(x, x)
+		px.Mul(px, x).Add(px, big.NewInt(3)).Mod(px, fieldModulus)
+		if new(big.Int).Exp(px, legendreExp, fieldModulus).Cmp(big.NewInt(1)) != 0 {
+			continue
+		}
+		y := new(big.Int).Exp(px, sqrtExp, fieldModulus)
+		if h.Bit(255) != y.Bit(0) {
+			y.Sub(fieldModulus, y)
+		}
+		p := new(bn256.G1)
+		if _, err := p.Unmarshal(append(common.LeftPadBytes(x.Bytes(), 32), common.LeftPadBytes(y.Bytes(), 32)...)); err != nil {
+			return nil, err
+		}
+		return p, nil
+	}
+	return nil, fmt.Errorf("hash to G1 failed")
+}
+
+// SignWithDST signs message under the domain separation tag dst, for
+// BGLS.checkSignatureWithDST. Committed seals are signed under "MAP_BLS_SIG_V1".
+func SignWithDST(secret *big.Int, dst, message []byte) (*bn256.G1, error) {
+	m, err := DomainMessage(dst, message)
+	if err != nil {
+		return nil, err
+	}
+	h, err := HashToG1TryAndIncrement(m)
+	if err != nil {
+		return nil, err
+	}
+	return new(bn256.G1).ScalarMult(h, secret), nil
+}
@@ -0,0 +1,44 @@
+package types
+
+import (
+	"math/big"
+	"testing"
+
+	bn256 "github.com/ethereum/go-ethereum/crypto/bn256/cloudflare"
+)
+
+func TestSignWithDST(t *testing.T) {
+	secret := big.NewInt(0x1234567890)
+	key := new(bn256.G2).ScalarBaseMult(secret)
+	message := []byte("abcefghi")
+
+	sig, err := SignWithDST(secret, []byte("MAP_BLS_SIG_V1"), message)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// e(sig, -g2) * e(H(m), key) == 1
+	negG2 := new(bn256.G2).Neg(new(bn256.G2).ScalarBaseMult(big.NewInt(1)))
+	for _, tc := range []struct {
+		dst  string
+		want bool
+	}{
+		{"MAP_BLS_SIG_V1", true},
+		{"MAP_BLS_EPOCH_V1", false},
+	} {
+		m, err := DomainMessage([]byte(tc.dst), message)
+		if err != nil {
+			t.Fatal(err)
+		}
+		h, err := HashToG1TryAndIncrement(m)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := bn256.PairingCheck([]*bn256.G1{sig, h}, []*bn256.G2{negG2, key}); got != tc.want {
+			t.Errorf("signature under %s checks %v, want %v", tc.dst, got, tc.want)
+		}
+	}
+
+	if _, err := SignWithDST(secret, nil, message); err == nil {
+		t.Error("empty tag accepted")
+	}
+}
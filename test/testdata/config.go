@@ -0,0 +1,121 @@
+package types
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+// ExtraLayout is how the istanbul extra of the headers from Block on is laid out, see
+// IstanbulExtra.Layout.
+type ExtraLayout struct {
+	Block        uint64
+	Vanity       int
+	HasG1PubKeys bool
+}
+
+// ChainConfig is what the LightNode contract is deployed with, the Go side of
+// ForkSchedule.ChainConfig. A nil fork block means the fork never activates.
+type ChainConfig struct {
+	EpochSize          uint64
+	CheckpointInterval uint64   // 1 keeps every header
+	BaseFeeBlock       *big.Int // EIP-1559
+	BlobGasBlock       *big.Int // EIP-4844 and EIP-4788
+	ExtraLayouts       []ExtraLayout
+}
+
+func isForked(fork, number *big.Int) bool {
+	return fork != nil && number.Cmp(fork) >= 0
+}
+
+func (c *ChainConfig) IsBaseFee(number *big.Int) bool { return isForked(c.BaseFeeBlock, number) }
+func (c *ChainConfig) IsBlobGas(number *big.Int) bool { return isForked(c.BlobGasBlock, number) }
+
+// Validate makes the checks of ForkSchedule.validate.
+func (c *ChainConfig) Validate() error {
+	if c.EpochSize == 0 {
+		return fmt.Errorf("zero epoch size")
+	}
+	if c.CheckpointInterval == 0 {
+		return fmt.Errorf("zero checkpoint interval")
+	}
+	if c.BlobGasBlock != nil && (c.BaseFeeBlock == nil || c.BlobGasBlock.Cmp(c.BaseFeeBlock) < 0) {
+		return fmt.Errorf("blob gas block %v before base fee block %v", c.BlobGasBlock, c.BaseFeeBlock)
+	}
+	if len(c.ExtraLayouts) == 0 || c.ExtraLayouts[0].Block != 0 {
+		return fmt.Errorf("no extra layout from block 0")
+	}
+	for i, l := range c.ExtraLayouts {
+		if i > 0 && l.Block <= c.ExtraLayouts[i-1].Block {
+			return fmt.Errorf("extra layout %d at block %d not after block %d", i, l.Block, c.ExtraLayouts[i-1].Block)
+		}
+		if l.Vanity <= 0 || l.Vanity > 256 {
+			return fmt.Errorf("extra layout %d has vanity %d", i, l.Vanity)
+		}
+	}
+	return nil
+}
+
+// ExtraLayoutAt returns the extra layout of the header at number.
+func (c *ChainConfig) ExtraLayoutAt(number uint64) ExtraLayout {
+	i := len(c.ExtraLayouts) - 1
+	for c.ExtraLayouts[i].Block > number {
+		i--
+	}
+	return c.ExtraLayouts[i]
+}
+
+// CheckHeader reports whether h carries exactly the optional fields of the forks
+// active at its number, as ForkSchedule.checkHeader does.
+func (c *ChainConfig) CheckHeader(h *Header) error {
+	if baseFee := c.IsBaseFee(h.Number); baseFee != (h.BaseFee != nil) {
+		return fmt.Errorf("header %v: base fee present %v, want %v", h.Number, h.BaseFee != nil, baseFee)
+	}
+	blobGas := c.IsBlobGas(h.Number)
+	if blobGas != (h.BlobGasUsed != nil) || blobGas != (h.ExcessBlobGas != nil) || blobGas != (h.ParentBeaconRoot != nil) {
+		return fmt.Errorf("header %v: blob gas fields do not match fork, want present %v", h.Number, blobGas)
+	}
+	return nil
+}
+
+// IsCheckpoint reports whether the light node stores the header at number, see
+// LightNode.isCheckpoint.
+func (c *ChainConfig) IsCheckpoint(number uint64) bool {
+	return number%c.CheckpointInterval == 0 || number%c.EpochSize == 0
+}
+
+// HeaderSegment returns the rlp headers LightNode.proveHeaderBetweenCheckpoints takes to
+// prove the header at number: the headers from it up to the next checkpoint, or up to
+// head if the light node has not reached that checkpoint yet. blocks must be ascending
+// and cover the range.
+func (c *ChainConfig) HeaderSegment(blocks Blocks, number, head uint64) ([][]byte, error) {
+	if number > head {
+		return nil, fmt.Errorf("header %d is past the head %d", number, head)
+	}
+	var segment [][]byte
+	var parent *Header
+	for _, b := range blocks {
+		n := b.NumberU64()
+		if n < number {
+			continue
+		}
+		h := b.Header()
+		if parent == nil && n != number {
+			return nil, fmt.Errorf("no block %d", number)
+		}
+		if parent != nil && (n != parent.Number.Uint64()+1 || h.ParentHash != parent.Hash()) {
+			return nil, fmt.Errorf("block %d does not extend block %v", n, parent.Number)
+		}
+		enc, err := rlp.EncodeToBytes(h)
+		if err != nil {
+			return nil, err
+		}
+		segment = append(segment, enc)
+		if n == head || c.IsCheckpoint(n) {
+			return segment, nil
+		}
+		parent = h
+	}
+	return nil, fmt.Errorf("blocks end before the checkpoint after %d", number)
+}
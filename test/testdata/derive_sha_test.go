@@ -0,0 +1,140 @@
+package types
+
+import (
+	"math/big"
+	"math/rand"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethdb/memorydb"
+	"github.com/ethereum/go-ethereum/trie"
+)
+
+// the TxHash and ReceiptHash of a block are derived with a StackTrie, the proofs MPTVerify checks
+// against them come from the trie that keeps its nodes. both have to give the same root for any
+// list, including the sizes where the index keys change length or the leaves get embedded
+
+// the keys are rlp(i): 0x80 for 0, one byte up to 0x7f and two from 0x80 on
+var deriveShaSizes = []int{0, 1, 2, 3, 15, 16, 17, 127, 128, 129, 130, 255, 256, 257, 1000}
+
+func newLegacyTrie(t *testing.T) *trie.Trie {
+	tr, err := trie.New(common.Hash{}, trie.NewDatabase(memorydb.New()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tr
+}
+
+func randomBig(rnd *rand.Rand, bits int) *big.Int {
+	return new(big.Int).Rand(rnd, new(big.Int).Lsh(big.NewInt(1), uint(bits)))
+}
+
+func randomAddress(rnd *rand.Rand) *common.Address {
+	var a common.Address
+	rnd.Read(a[:])
+	return &a
+}
+
+func randomBytes(rnd *rand.Rand, n int) []byte {
+	b := make([]byte, n)
+	rnd.Read(b)
+	return b
+}
+
+// legacy, access list and dynamic fee transactions. small ones have every field zero, so their
+// leaves are shorter than 32 bytes and embedded in the branch above them
+func randomTransactions(rnd *rand.Rand, n int, small bool) Transactions {
+	txs := make(Transactions, n)
+	for i := range txs {
+		if small {
+			txs[i] = NewTx(&LegacyTx{})
+			continue
+		}
+		to := randomAddress(rnd)
+		if rnd.Intn(8) == 0 {
+			to = nil // contract creation
+		}
+		data := randomBytes(rnd, rnd.Intn(100))
+		v, r, s := randomBig(rnd, 8), randomBig(rnd, 256), randomBig(rnd, 256)
+		accessList := AccessList{{Address: *randomAddress(rnd), StorageKeys: []common.Hash{common.BytesToHash(randomBytes(rnd, 32))}}}
+
+		switch rnd.Intn(3) {
+		case 0:
+			txs[i] = NewTx(&LegacyTx{
+				Nonce: rnd.Uint64(), GasPrice: randomBig(rnd, 64), Gas: rnd.Uint64(), To: to,
+				Value: randomBig(rnd, 128), Data: data, V: v, R: r, S: s,
+			})
+		case 1:
+			txs[i] = NewTx(&AccessListTx{
+				ChainID: big.NewInt(22776), Nonce: rnd.Uint64(), GasPrice: randomBig(rnd, 64), Gas: rnd.Uint64(), To: to,
+				Value: randomBig(rnd, 128), Data: data, AccessList: accessList, V: v, R: r, S: s,
+			})
+		default:
+			txs[i] = NewTx(&DynamicFeeTx{
+				ChainID: big.NewInt(22776), Nonce: rnd.Uint64(), GasTipCap: randomBig(rnd, 64), GasFeeCap: randomBig(rnd, 64),
+				Gas: rnd.Uint64(), To: to, Value: randomBig(rnd, 128), Data: data, AccessList: accessList, V: v, R: r, S: s,
+			})
+		}
+	}
+	return txs
+}
+
+// legacy and typed receipts with up to 3 logs and their bloom
+func randomReceipts(rnd *rand.Rand, n int) Receipts {
+	receipts := make(Receipts, n)
+	var gas uint64
+	for i := range receipts {
+		gas += uint64(rnd.Intn(1000000))
+		r := &Receipt{Type: uint8(rnd.Intn(3)), Status: uint64(rnd.Intn(2)), CumulativeGasUsed: gas}
+		for j := rnd.Intn(4); j > 0; j-- {
+			topics := make([]common.Hash, rnd.Intn(4))
+			for k := range topics {
+				topics[k] = common.BytesToHash(randomBytes(rnd, 32))
+			}
+			r.Logs = append(r.Logs, &Log{Address: *randomAddress(rnd), Topics: topics, Data: randomBytes(rnd, rnd.Intn(100))})
+		}
+		r.Bloom = CreateBloom(Receipts{r})
+		receipts[i] = r
+	}
+	return receipts
+}
+
+func TestDeriveShaStackTrie(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	sizes := append([]int{}, deriveShaSizes...)
+	for i := 0; i < 20; i++ {
+		sizes = append(sizes, 1+rnd.Intn(300))
+	}
+
+	for _, n := range sizes {
+		for _, small := range []bool{false, true} {
+			txs := randomTransactions(rnd, n, small)
+			got, want := DeriveSha(txs, trie.NewStackTrie(nil)), DeriveSha(txs, newLegacyTrie(t))
+			if got != want {
+				t.Errorf("%d transactions (small %v): stack trie root %x, trie root %x", n, small, got, want)
+			}
+		}
+
+		receipts := randomReceipts(rnd, n)
+		got, want := DeriveSha(receipts, trie.NewStackTrie(nil)), DeriveSha(receipts, newLegacyTrie(t))
+		if got != want {
+			t.Errorf("%d receipts: stack trie root %x, trie root %x", n, got, want)
+		}
+	}
+
+	if root := DeriveSha(Transactions{}, trie.NewStackTrie(nil)); root != EmptyRootHash {
+		t.Errorf("empty list root %x, want %x", root, EmptyRootHash)
+	}
+}
+
+// the same StackTrie is reused by consensus code, DeriveSha has to reset it in between
+func TestDeriveShaReusedStackTrie(t *testing.T) {
+	rnd := rand.New(rand.NewSource(2))
+	st := trie.NewStackTrie(nil)
+	for _, n := range deriveShaSizes {
+		txs := randomTransactions(rnd, n, n%2 == 0)
+		if got, want := DeriveSha(txs, st), DeriveSha(txs, newLegacyTrie(t)); got != want {
+			t.Errorf("%d transactions: reused stack trie root %x, trie root %x", n, got, want)
+		}
+	}
+}
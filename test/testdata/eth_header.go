@@ -0,0 +1,80 @@
+package types
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// EthHeader is the block header of go-ethereum, for source chains that are vanilla
+// Ethereum rather than atlas. It differs from Header in UncleHash and Difficulty, which
+// atlas dropped, and in the forks that add optional fields. EthHeaderCodec decodes it.
+type EthHeader struct {
+	ParentHash  common.Hash    `json:"parentHash"       gencodec:"required"`
+	UncleHash   common.Hash    `json:"sha3Uncles"       gencodec:"required"`
+	Coinbase    common.Address `json:"miner"`
+	Root        common.Hash    `json:"stateRoot"        gencodec:"required"`
+	TxHash      common.Hash    `json:"transactionsRoot" gencodec:"required"`
+	ReceiptHash common.Hash    `json:"receiptsRoot"     gencodec:"required"`
+	Bloom       Bloom          `json:"logsBloom"        gencodec:"required"`
+	Difficulty  *big.Int       `json:"difficulty"       gencodec:"required"`
+	Number      *big.Int       `json:"number"           gencodec:"required"`
+	GasLimit    uint64         `json:"gasLimit"         gencodec:"required"`
+	GasUsed     uint64         `json:"gasUsed"          gencodec:"required"`
+	Time        uint64         `json:"timestamp"        gencodec:"required"`
+	Extra       []byte         `json:"extraData"        gencodec:"required"`
+	MixDigest   common.Hash    `json:"mixHash"`
+	Nonce       BlockNonce     `json:"nonce"`
+
+	// BaseFee was added by EIP-1559 and is ignored in legacy headers.
+	BaseFee *big.Int `json:"baseFeePerGas" rlp:"optional"`
+
+	// WithdrawalsHash was added by EIP-4895 and is ignored in legacy headers.
+	WithdrawalsHash *common.Hash `json:"withdrawalsRoot" rlp:"optional"`
+
+	// BlobGasUsed was added by EIP-4844 and is ignored in legacy headers.
+	BlobGasUsed *uint64 `json:"blobGasUsed" rlp:"optional"`
+
+	// ExcessBlobGas was added by EIP-4844 and is ignored in legacy headers.
+	ExcessBlobGas *uint64 `json:"excessBlobGas" rlp:"optional"`
+
+	// ParentBeaconRoot was added by EIP-4788 and is ignored in legacy headers.
+	ParentBeaconRoot *common.Hash `json:"parentBeaconBlockRoot" rlp:"optional"`
+
+	// RequestsHash was added by EIP-7685 and is ignored in legacy headers.
+	RequestsHash *common.Hash `json:"requestsHash" rlp:"optional"`
+}
+
+// Hash returns the keccak256 hash of the header's rlp, there is no seal to leave out.
+func (h *EthHeader) Hash() common.Hash {
+	return rlpHash(h)
+}
+
+// SanityCheck checks that the optional fields come in the order of the forks that added
+// them, which is what EthHeaderCodec.decode accepts.
+func (h *EthHeader) SanityCheck() error {
+	if h.Number == nil || h.Difficulty == nil {
+		return fmt.Errorf("missing number or difficulty")
+	}
+	present := []bool{
+		h.BaseFee != nil,
+		h.WithdrawalsHash != nil,
+		h.BlobGasUsed != nil && h.ExcessBlobGas != nil && h.ParentBeaconRoot != nil,
+		h.RequestsHash != nil,
+	}
+	if (h.BlobGasUsed != nil || h.ExcessBlobGas != nil || h.ParentBeaconRoot != nil) && !present[2] {
+		return fmt.Errorf("incomplete cancun fields")
+	}
+	for i := 1; i < len(present); i++ {
+		if present[i] && !present[i-1] {
+			return fmt.Errorf("optional field %d without the ones before it", i)
+		}
+	}
+	return nil
+}
+
+// IsPostMerge reports whether h is a proof of stake header, see EthHeaderCodec.isPostMerge.
+func (h *EthHeader) IsPostMerge() bool {
+	return h.Difficulty.Sign() == 0 && h.UncleHash == EmptyUncleHash && h.Nonce == BlockNonce{}
+}
@@ -0,0 +1,70 @@
+package types
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+// the go side of test/testRLPFuzz.js: whatever decodes is canonical, so it encodes back to the
+// same bytes, which is what the contracts require of their input
+
+func FuzzHeaderRLP(f *testing.F) {
+	for _, tc := range loadVectors(f).Headers {
+		f.Add([]byte(tc.Rlp))
+	}
+	f.Fuzz(func(t *testing.T, data []byte) {
+		var h Header
+		if err := rlp.DecodeBytes(data, &h); err != nil {
+			return
+		}
+		enc, err := rlp.EncodeToBytes(&h)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(enc, data) {
+			t.Fatalf("decoded %x, encodes to %x", data, enc)
+		}
+		h.Hash()
+	})
+}
+
+func FuzzEpochSnarkData(f *testing.F) {
+	f.Add([]byte{0xc2, 0x2f, 0x80})
+	f.Add(append([]byte{0xf8, 68, 0x81, 0xff, 0xb8, 64}, make([]byte, 64)...))
+	f.Fuzz(func(t *testing.T, data []byte) {
+		var e EpochSnarkData
+		if err := rlp.DecodeBytes(data, &e); err != nil {
+			return
+		}
+		enc, err := rlp.EncodeToBytes(&e)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(enc, data) {
+			t.Fatalf("decoded %x, encodes to %x", data, enc)
+		}
+	})
+}
+
+// an extra that IstanbulExtra.decode would take has to filter, as HeaderCodec.hash does
+func FuzzIstanbulExtra(f *testing.F) {
+	v := loadVectors(f)
+	for _, tc := range v.Headers {
+		var h Header
+		if err := rlp.DecodeBytes(tc.Rlp, &h); err != nil {
+			f.Fatal(err)
+		}
+		f.Add(h.Extra)
+	}
+	f.Fuzz(func(t *testing.T, extra []byte) {
+		h := &Header{Extra: extra}
+		if _, err := ExtractIstanbulExtra(h); err != nil {
+			return
+		}
+		if IstanbulFilteredHeader(h, true) == nil || IstanbulFilteredHeader(h, false) == nil {
+			t.Fatalf("extra %x decodes but does not filter", extra)
+		}
+	})
+}
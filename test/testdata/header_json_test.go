@@ -0,0 +1,159 @@
+package types
+
+import (
+	"bytes"
+	"encoding/json"
+	"math/big"
+	"math/rand"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+// random header with the optional fields up to the last one present set, the way SanityCheck
+// allows them. zero is a value like any other, a present zero BaseFee is not a nil one
+func randomHeader(rnd *rand.Rand, optional int) *Header {
+	h := &Header{
+		ParentHash:  common.BytesToHash(randomBytes(rnd, 32)),
+		Coinbase:    *randomAddress(rnd),
+		Root:        common.BytesToHash(randomBytes(rnd, 32)),
+		TxHash:      common.BytesToHash(randomBytes(rnd, 32)),
+		ReceiptHash: common.BytesToHash(randomBytes(rnd, 32)),
+		Number:      randomBig(rnd, 64),
+		GasLimit:    rnd.Uint64(),
+		GasUsed:     rnd.Uint64(),
+		Time:        rnd.Uint64(),
+		Extra:       randomBytes(rnd, rnd.Intn(300)),
+		MixDigest:   common.BytesToHash(randomBytes(rnd, 32)),
+		Nonce:       EncodeNonce(rnd.Uint64()),
+	}
+	if rnd.Intn(2) == 0 {
+		rnd.Read(h.Bloom[:])
+	}
+	value := func() uint64 {
+		if rnd.Intn(4) == 0 {
+			return 0
+		}
+		return rnd.Uint64()
+	}
+	if optional >= 1 {
+		h.BaseFee = randomBig(rnd, 1+rnd.Intn(256))
+	}
+	if optional >= 2 {
+		used, excess := value(), value()
+		h.BlobGasUsed, h.ExcessBlobGas = &used, &excess
+	}
+	if optional >= 3 {
+		root := common.BytesToHash(randomBytes(rnd, 32))
+		h.ParentBeaconRoot = &root
+	}
+	return h
+}
+
+// JSON -> struct -> RLP loses nothing: every header marshals to JSON that unmarshals to a
+// header with the same rlp, hash and JSON
+func TestHeaderJSONRoundTrip(t *testing.T) {
+	rnd := rand.New(rand.NewSource(3))
+	for i := 0; i < 200; i++ {
+		h := randomHeader(rnd, i%4)
+		want, err := rlp.EncodeToBytes(h)
+		if err != nil {
+			t.Fatal(err)
+		}
+		enc, err := json.Marshal(h)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var dec Header
+		if err := json.Unmarshal(enc, &dec); err != nil {
+			t.Fatalf("header %d: %v\n%s", i, err, enc)
+		}
+		got, err := rlp.EncodeToBytes(&dec)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(got, want) {
+			t.Errorf("header %d: rlp after JSON round trip\n%x\nwant\n%x\nJSON %s", i, got, want, enc)
+		}
+		if dec.Hash() != h.Hash() {
+			t.Errorf("header %d: hash %x after JSON round trip, want %x", i, dec.Hash(), h.Hash())
+		}
+		if again, _ := json.Marshal(&dec); !bytes.Equal(again, enc) {
+			t.Errorf("header %d: JSON changed in a round trip\n%s\nwant\n%s", i, again, enc)
+		}
+	}
+}
+
+// the optional fields are JSON quantities, null when absent, as nodes return them
+func TestHeaderJSONOptionalFields(t *testing.T) {
+	rnd := rand.New(rand.NewSource(4))
+	for optional, fields := range [][]string{
+		{`"baseFeePerGas":null`, `"blobGasUsed":null`, `"excessBlobGas":null`, `"parentBeaconBlockRoot":null`},
+		{`"baseFeePerGas":"0x`, `"blobGasUsed":null`, `"excessBlobGas":null`, `"parentBeaconBlockRoot":null`},
+		{`"baseFeePerGas":"0x`, `"blobGasUsed":"0x`, `"excessBlobGas":"0x`, `"parentBeaconBlockRoot":null`},
+		{`"baseFeePerGas":"0x`, `"blobGasUsed":"0x`, `"excessBlobGas":"0x`, `"parentBeaconBlockRoot":"0x`},
+	} {
+		enc, err := json.Marshal(randomHeader(rnd, optional))
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, f := range fields {
+			if !strings.Contains(string(enc), f) {
+				t.Errorf("%d optional fields: no %s in %s", optional, f, enc)
+			}
+		}
+	}
+
+	zero := uint64(0)
+	h := randomHeader(rnd, 0)
+	h.BaseFee, h.BlobGasUsed, h.ExcessBlobGas = new(big.Int), &zero, &zero
+	enc, _ := json.Marshal(h)
+	var dec Header
+	if err := json.Unmarshal(enc, &dec); err != nil {
+		t.Fatal(err)
+	}
+	if dec.BaseFee == nil || dec.BaseFee.Sign() != 0 || dec.BlobGasUsed == nil || dec.ExcessBlobGas == nil {
+		t.Errorf("present zero fields lost in %s", enc)
+	}
+}
+
+func TestHeaderJSONNode(t *testing.T) {
+	data, err := os.ReadFile("head.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var res struct {
+		Result json.RawMessage `json:"result"`
+	}
+	if err := json.Unmarshal(data, &res); err != nil {
+		t.Fatal(err)
+	}
+	var node struct {
+		Hash common.Hash `json:"hash"`
+	}
+	if err := json.Unmarshal(res.Result, &node); err != nil {
+		t.Fatal(err)
+	}
+
+	var h Header
+	if err := json.Unmarshal(res.Result, &h); err != nil {
+		t.Fatal(err)
+	}
+	if h.BaseFee == nil || h.Hash() != node.Hash {
+		t.Errorf("header of head.json hashes to %x with base fee %v, want %x", h.Hash(), h.BaseFee, node.Hash)
+	}
+
+	for _, field := range []string{"parentHash", "miner", "number", "extraData", "logsBloom"} {
+		var fields map[string]json.RawMessage
+		json.Unmarshal(res.Result, &fields)
+		delete(fields, field)
+		enc, _ := json.Marshal(fields)
+		if err := json.Unmarshal(enc, new(Header)); err == nil {
+			t.Errorf("header without %s unmarshaled", field)
+		}
+	}
+}
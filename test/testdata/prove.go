@@ -0,0 +1,159 @@
+package types
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/ethdb/memorydb"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/ethereum/go-ethereum/trie"
+)
+
+// ReceiptProof returns the trie nodes proving receipts[index] against the ReceiptHash that
+// DeriveSha computes for receipts, in the order MPTVerify.verifyReceipt takes them.
+func ReceiptProof(receipts Receipts, index int) ([][]byte, error) {
+	return deriveProof(receipts, index)
+}
+
+// TransactionProof returns the trie nodes proving txs[index] against the TxHash that
+// DeriveSha computes for txs, in the order MPTVerify.verifyTransaction takes them.
+func TransactionProof(txs Transactions, index int) ([][]byte, error) {
+	return deriveProof(txs, index)
+}
+
+// deriveProof builds the trie of DeriveSha with a StackTrie that writes the nodes it hashes to
+// a memory database, and reads the path to the key of list[index] back from there, root first.
+// nodes shorter than 32 bytes are embedded in their parent and not listed, as with Trie.Prove.
+func deriveProof(list DerivableList, index int) ([][]byte, error) {
+	if index < 0 || index >= list.Len() {
+		return nil, fmt.Errorf("index %d out of range [0, %d)", index, list.Len())
+	}
+
+	db := memorydb.New()
+	st := trie.NewStackTrie(db)
+	value := new(bytes.Buffer)
+	insert := func(i int) {
+		value.Reset()
+		list.EncodeIndex(i, value)
+		st.Update(rlp.AppendUint64(nil, uint64(i)), common.CopyBytes(value.Bytes()))
+	}
+	// a StackTrie takes the keys in order, as DeriveSha inserts them: rlp(0) is 0x80
+	for i := 1; i < list.Len() && i <= 0x7f; i++ {
+		insert(i)
+	}
+	insert(0)
+	for i := 0x80; i < list.Len(); i++ {
+		insert(i)
+	}
+	// Commit also writes the root when it is shorter than 32 bytes
+	root, err := st.Commit()
+	if err != nil {
+		return nil, err
+	}
+	return readProof(db, root, rlp.AppendUint64(nil, uint64(index)))
+}
+
+// readProof collects the nodes on the path from root to key out of db, where they are keyed by
+// their hash. the path ends at the leaf of key, or where the trie shows key is absent.
+func readProof(db ethdb.KeyValueReader, root common.Hash, key []byte) ([][]byte, error) {
+	path := keyNibbles(key)
+	enc, err := db.Get(root[:])
+	if err != nil {
+		return nil, fmt.Errorf("missing root node %x: %v", root, err)
+	}
+	proof := [][]byte{enc}
+
+	for node := enc; ; {
+		elems, _, err := rlp.SplitList(node)
+		if err != nil {
+			return nil, err
+		}
+		count, err := rlp.CountValues(elems)
+		if err != nil {
+			return nil, err
+		}
+
+		var child []byte
+		switch count {
+		case 17:
+			if len(path) == 0 {
+				return proof, nil
+			}
+			if child, err = nthValue(elems, int(path[0])); err != nil {
+				return nil, err
+			}
+			path = path[1:]
+		case 2:
+			compact, rest, err := rlp.SplitString(elems)
+			if err != nil {
+				return nil, err
+			}
+			nibbles, isLeaf := compactNibbles(compact)
+			if len(path) < len(nibbles) || !bytes.Equal(path[:len(nibbles)], nibbles) {
+				return proof, nil
+			}
+			path = path[len(nibbles):]
+			if isLeaf {
+				return proof, nil
+			}
+			child = rest
+		default:
+			return nil, fmt.Errorf("trie node with %d items", count)
+		}
+
+		kind, ref, _, err := rlp.Split(child)
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case kind == rlp.List:
+			node = child // embedded
+		case len(ref) == 0:
+			return proof, nil
+		default:
+			if node, err = db.Get(ref); err != nil {
+				return nil, fmt.Errorf("missing trie node %x: %v", ref, err)
+			}
+			proof = append(proof, node)
+		}
+	}
+}
+
+// nthValue returns the rlp encoding of the n-th value in the concatenated values of a list
+func nthValue(elems []byte, n int) ([]byte, error) {
+	for ; n > 0; n-- {
+		_, _, rest, err := rlp.Split(elems)
+		if err != nil {
+			return nil, err
+		}
+		elems = rest
+	}
+	_, _, rest, err := rlp.Split(elems)
+	if err != nil {
+		return nil, err
+	}
+	return elems[:len(elems)-len(rest)], nil
+}
+
+func keyNibbles(key []byte) []byte {
+	nibbles := make([]byte, 0, 2*len(key))
+	for _, b := range key {
+		nibbles = append(nibbles, b>>4, b&0x0f)
+	}
+	return nibbles
+}
+
+// compactNibbles decodes the hex prefix encoded path of a short node
+func compactNibbles(compact []byte) (nibbles []byte, isLeaf bool) {
+	if len(compact) == 0 {
+		return nil, false
+	}
+	nibbles = keyNibbles(compact)
+	isLeaf = nibbles[0] >= 2
+	if nibbles[0]%2 == 1 {
+		return nibbles[1:], isLeaf
+	}
+	return nibbles[2:], isLeaf
+}
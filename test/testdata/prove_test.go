@@ -0,0 +1,197 @@
+package types
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"math/rand"
+	"os"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethdb/memorydb"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/ethereum/go-ethereum/trie"
+)
+
+var update = flag.Bool("update", false, "rewrite receipt_proofs.json from ReceiptProof")
+
+// proofList collects the nodes written by Trie.Prove, root first.
+type proofList [][]byte
+
+func (l *proofList) Put(key []byte, value []byte) error {
+	*l = append(*l, common.CopyBytes(value))
+	return nil
+}
+
+func (l *proofList) Delete(key []byte) error {
+	panic("not supported")
+}
+
+// checkProof checks proof of list[index] against the DeriveSha root with trie.VerifyProof, and
+// that it has the nodes Trie.Prove gives on a trie that keeps them
+func checkProof(t *testing.T, list DerivableList, index int, proof [][]byte) {
+	t.Helper()
+	key := rlp.AppendUint64(nil, uint64(index))
+	var want bytes.Buffer
+	list.EncodeIndex(index, &want)
+
+	db := memorydb.New()
+	for _, node := range proof {
+		db.Put(crypto.Keccak256(node), node)
+	}
+	value, err := trie.VerifyProof(DeriveSha(list, trie.NewStackTrie(nil)), key, db)
+	if err != nil || !bytes.Equal(value, want.Bytes()) {
+		t.Fatalf("item %d of %d: proof gives %x, %v, want %x", index, list.Len(), value, err, want.Bytes())
+	}
+
+	tr := newLegacyTrie(t)
+	DeriveSha(list, tr)
+	var nodes proofList
+	if err := tr.Prove(key, 0, &nodes); err != nil {
+		t.Fatal(err)
+	}
+	if len(nodes) != len(proof) {
+		t.Fatalf("item %d of %d: %d proof nodes, Trie.Prove gives %d", index, list.Len(), len(proof), len(nodes))
+	}
+	for i := range nodes {
+		if !bytes.Equal(nodes[i], proof[i]) {
+			t.Fatalf("item %d of %d: proof node %d is %x, Trie.Prove gives %x", index, list.Len(), i, proof[i], nodes[i])
+		}
+	}
+}
+
+func proofIndices(n int) []int {
+	var indices []int
+	for _, i := range []int{0, 1, n / 2, n - 1, 0x7f, 0x80} {
+		if i < n {
+			indices = append(indices, i)
+		}
+	}
+	return indices
+}
+
+func TestReceiptProof(t *testing.T) {
+	rnd := rand.New(rand.NewSource(5))
+	for _, n := range deriveShaSizes[1:] {
+		receipts := randomReceipts(rnd, n)
+		for _, i := range proofIndices(n) {
+			proof, err := ReceiptProof(receipts, i)
+			if err != nil {
+				t.Fatal(err)
+			}
+			checkProof(t, receipts, i, proof)
+		}
+	}
+}
+
+// small transactions have embedded leaves, which are part of their branch and not listed
+func TestTransactionProof(t *testing.T) {
+	rnd := rand.New(rand.NewSource(6))
+	for _, n := range deriveShaSizes[1:] {
+		for _, small := range []bool{false, true} {
+			txs := randomTransactions(rnd, n, small)
+			for _, i := range proofIndices(n) {
+				proof, err := TransactionProof(txs, i)
+				if err != nil {
+					t.Fatal(err)
+				}
+				checkProof(t, txs, i, proof)
+			}
+		}
+	}
+}
+
+func TestProofIndexRange(t *testing.T) {
+	receipts := randomReceipts(rand.New(rand.NewSource(7)), 3)
+	for _, i := range []int{-1, 3} {
+		if _, err := ReceiptProof(receipts, i); err == nil {
+			t.Errorf("proof of receipt %d of 3", i)
+		}
+	}
+	if _, err := TransactionProof(Transactions{}, 0); err == nil {
+		t.Errorf("proof in an empty list")
+	}
+}
+
+// receipt_proofs.json holds proofs of consensus encoded receipts for test/testMPTVerify.js,
+// which checks them with MPTVerify.verifyReceipt. go test -run TestReceiptProofVectors -update
+// rewrites the roots and proofs from the receipts in it
+type receiptProofSet struct {
+	Receipts []hexutil.Bytes `json:"receipts"`
+	Root     common.Hash     `json:"root"`
+	Proofs   []struct {
+		Index int             `json:"index"`
+		Proof []hexutil.Bytes `json:"proof"`
+	} `json:"proofs"`
+}
+
+func TestReceiptProofVectors(t *testing.T) {
+	data, err := os.ReadFile("receipt_proofs.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var sets []receiptProofSet
+	if err := json.Unmarshal(data, &sets); err != nil {
+		t.Fatal(err)
+	}
+
+	for s := range sets {
+		set := &sets[s]
+		receipts := make(Receipts, len(set.Receipts))
+		for i, enc := range set.Receipts {
+			receipts[i] = new(Receipt)
+			if err := receipts[i].UnmarshalBinary(enc); err != nil {
+				t.Fatalf("set %d receipt %d: %v", s, i, err)
+			}
+			// the encoding is consensus, so the receipt encodes back to it
+			var buf bytes.Buffer
+			Receipts{receipts[i]}.EncodeIndex(0, &buf)
+			if !bytes.Equal(buf.Bytes(), enc) {
+				t.Fatalf("set %d receipt %d: encodes to %x, want %x", s, i, buf.Bytes(), []byte(enc))
+			}
+		}
+
+		root := DeriveSha(receipts, trie.NewStackTrie(nil))
+		if *update {
+			set.Root = root
+		} else if root != set.Root {
+			t.Errorf("set %d: root %x, want %x", s, root, set.Root)
+		}
+		for p := range set.Proofs {
+			proof, err := ReceiptProof(receipts, set.Proofs[p].Index)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if *update {
+				set.Proofs[p].Proof = make([]hexutil.Bytes, len(proof))
+				for i := range proof {
+					set.Proofs[p].Proof[i] = proof[i]
+				}
+				continue
+			}
+			want := set.Proofs[p].Proof
+			if len(proof) != len(want) {
+				t.Errorf("set %d receipt %d: %d proof nodes, want %d", s, set.Proofs[p].Index, len(proof), len(want))
+				continue
+			}
+			for i := range proof {
+				if !bytes.Equal(proof[i], want[i]) {
+					t.Errorf("set %d receipt %d: proof node %d is %x, want %x", s, set.Proofs[p].Index, i, proof[i], []byte(want[i]))
+				}
+			}
+		}
+	}
+
+	if *update {
+		enc, err := json.MarshalIndent(sets, "", "  ")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile("receipt_proofs.json", append(enc, '\n'), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,226 @@
+package types
+
+import (
+	"bytes"
+	"encoding/json"
+	"math/big"
+	"os"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	bn256 "github.com/ethereum/go-ethereum/crypto/bn256/cloudflare"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+// coordinates and scalars are 32 byte big endian words
+type vectorG1 struct {
+	X, Y hexutil.Bytes
+}
+
+type vectorG2 struct {
+	Xr, Xi, Yr, Yi hexutil.Bytes
+}
+
+// vectors.json is written by scripts/gen-vectors.js, test/testVectors.js checks the
+// contracts against the same file.
+type vectors struct {
+	G1Add []struct {
+		A, B, Sum vectorG1
+	}
+	G1Mul []struct {
+		Point   vectorG1
+		Scalar  hexutil.Bytes
+		Product vectorG1
+	}
+	Signatures []struct {
+		Message hexutil.Bytes
+		Signers []struct {
+			Secret    hexutil.Bytes
+			Pubkey    vectorG2
+			Signature vectorG1
+		}
+		Signature vectorG1
+		Pubkey    vectorG2
+	}
+	Headers []struct {
+		Rlp                  hexutil.Bytes
+		Hash                 common.Hash
+		SigHash              common.Hash
+		Round                uint64
+		CommittedSealMessage hexutil.Bytes
+	}
+	InvalidExtraHeaders []struct {
+		Rlp     hexutil.Bytes
+		Hash    common.Hash
+		SigHash common.Hash
+	}
+	AggregatedSeals []struct {
+		Validators []struct {
+			Secret hexutil.Bytes
+			Pubkey vectorG2
+		}
+		Genesis   common.Hash
+		Rlp       hexutil.Bytes
+		Signers   []int
+		Bitmap    int64
+		Signature hexutil.Bytes
+	}
+}
+
+func (p vectorG1) point(t *testing.T) *bn256.G1 {
+	g := new(bn256.G1)
+	if _, err := g.Unmarshal(append(common.CopyBytes(p.X), p.Y...)); err != nil {
+		t.Fatal(err)
+	}
+	return g
+}
+
+// bn256 orders the G2 coordinates imaginary part first
+func (p vectorG2) point(t *testing.T) *bn256.G2 {
+	var b []byte
+	for _, v := range []hexutil.Bytes{p.Xi, p.Xr, p.Yi, p.Yr} {
+		b = append(b, v...)
+	}
+	g := new(bn256.G2)
+	if _, err := g.Unmarshal(b); err != nil {
+		t.Fatal(err)
+	}
+	return g
+}
+
+func loadVectors(tb testing.TB) *vectors {
+	data, err := os.ReadFile("vectors.json")
+	if err != nil {
+		tb.Fatal(err)
+	}
+	v := new(vectors)
+	if err := json.Unmarshal(data, v); err != nil {
+		tb.Fatal(err)
+	}
+	return v
+}
+
+func TestVectorsCurve(t *testing.T) {
+	v := loadVectors(t)
+	for i, tc := range v.G1Add {
+		sum := new(bn256.G1).Add(tc.A.point(t), tc.B.point(t))
+		if !bytes.Equal(sum.Marshal(), tc.Sum.point(t).Marshal()) {
+			t.Errorf("g1Add %d: sum mismatch", i)
+		}
+	}
+	for i, tc := range v.G1Mul {
+		product := new(bn256.G1).ScalarMult(tc.Point.point(t), new(big.Int).SetBytes(tc.Scalar))
+		if !bytes.Equal(product.Marshal(), tc.Product.point(t).Marshal()) {
+			t.Errorf("g1Mul %d: product mismatch", i)
+		}
+	}
+}
+
+func TestVectorsSignatures(t *testing.T) {
+	v := loadVectors(t)
+	negG2 := new(bn256.G2).Neg(new(bn256.G2).ScalarBaseMult(big.NewInt(1)))
+	for i, tc := range v.Signatures {
+		// the js tests hash messages to keccak(m) * g1
+		k := new(big.Int).Mod(new(big.Int).SetBytes(crypto.Keccak256(tc.Message)), bn256.Order)
+		h := new(bn256.G1).ScalarBaseMult(k)
+
+		for j, s := range tc.Signers {
+			secret := new(big.Int).SetBytes(s.Secret)
+			if !bytes.Equal(new(bn256.G2).ScalarBaseMult(secret).Marshal(), s.Pubkey.point(t).Marshal()) {
+				t.Errorf("signature %d signer %d: public key mismatch", i, j)
+			}
+			if !bytes.Equal(new(bn256.G1).ScalarMult(h, secret).Marshal(), s.Signature.point(t).Marshal()) {
+				t.Errorf("signature %d signer %d: signature mismatch", i, j)
+			}
+		}
+		if !bn256.PairingCheck([]*bn256.G1{tc.Signature.point(t), h}, []*bn256.G2{negG2, tc.Pubkey.point(t)}) {
+			t.Errorf("signature %d: aggregate does not verify", i)
+		}
+	}
+}
+
+func TestVectorsHeaders(t *testing.T) {
+	v := loadVectors(t)
+	for i, tc := range v.Headers {
+		var h Header
+		if err := rlp.DecodeBytes(tc.Rlp, &h); err != nil {
+			t.Fatalf("header %d: %v", i, err)
+		}
+		if got := h.Hash(); got != tc.Hash {
+			t.Errorf("header %d: hash %x, want %x", i, got, tc.Hash)
+		}
+		if got := rlpHash(IstanbulFilteredHeader(&h, false)); got != tc.SigHash {
+			t.Errorf("header %d: sig hash %x, want %x", i, got, tc.SigHash)
+		}
+		// PrepareCommittedSeal: hash || round || MsgCommit
+		msg := append(append(tc.Hash.Bytes(), new(big.Int).SetUint64(tc.Round).Bytes()...), 2)
+		if !bytes.Equal(msg, tc.CommittedSealMessage) {
+			t.Errorf("header %d: committed seal message mismatch", i)
+		}
+	}
+}
+
+// headers whose extra is not an istanbul extra hash over their whole encoding, and filter to
+// nil for the seal
+func TestVectorsInvalidExtra(t *testing.T) {
+	v := loadVectors(t)
+	for i, tc := range v.InvalidExtraHeaders {
+		var h Header
+		if err := rlp.DecodeBytes(tc.Rlp, &h); err != nil {
+			t.Fatalf("header %d: %v", i, err)
+		}
+		if _, err := ExtractIstanbulExtra(&h); err == nil {
+			t.Errorf("header %d: extra %x decodes", i, h.Extra)
+		}
+		if got := h.Hash(); got != tc.Hash {
+			t.Errorf("header %d: hash %x, want %x", i, got, tc.Hash)
+		}
+		if got := rlpHash(IstanbulFilteredHeader(&h, false)); got != tc.SigHash {
+			t.Errorf("header %d: sig hash %x, want %x", i, got, tc.SigHash)
+		}
+	}
+}
+
+// AggregateEpochSnarkData builds the seal test/testVectors.js has LightNode verify, from the
+// signatures of the validators in the order they signed
+func TestVectorsAggregatedSeals(t *testing.T) {
+	v := loadVectors(t)
+	for i, tc := range v.AggregatedSeals {
+		var h Header
+		if err := rlp.DecodeBytes(tc.Rlp, &h); err != nil {
+			t.Fatalf("seal %d: %v", i, err)
+		}
+		ist, err := ExtractIstanbulExtra(&h)
+		if err != nil {
+			t.Fatalf("seal %d: %v", i, err)
+		}
+		hash := h.Hash()
+		msg := append(append(hash.Bytes(), ist.AggregatedSeal.Round.Bytes()...), 2)
+
+		sigs := make([][]byte, len(tc.Signers))
+		for j, index := range tc.Signers {
+			secret := new(big.Int).SetBytes(tc.Validators[index].Secret)
+			if !bytes.Equal(new(bn256.G2).ScalarBaseMult(secret).Marshal(), tc.Validators[index].Pubkey.point(t).Marshal()) {
+				t.Errorf("seal %d validator %d: public key mismatch", i, index)
+			}
+			sig, err := SignWithDST(secret, []byte("MAP_BLS_SIG_V1"), msg)
+			if err != nil {
+				t.Fatal(err)
+			}
+			sigs[j] = sig.Marshal()
+		}
+
+		snark, err := AggregateEpochSnarkData(sigs, tc.Signers, len(tc.Validators))
+		if err != nil {
+			t.Fatalf("seal %d: %v", i, err)
+		}
+		if snark.Bitmap.Cmp(big.NewInt(tc.Bitmap)) != 0 || snark.Bitmap.Cmp(ist.AggregatedSeal.Bitmap) != 0 {
+			t.Errorf("seal %d: bitmap %b, want %b", i, snark.Bitmap, tc.Bitmap)
+		}
+		if !bytes.Equal(snark.Signature, tc.Signature) || !bytes.Equal(snark.Signature, ist.AggregatedSeal.Signature) {
+			t.Errorf("seal %d: signature %x, want %x", i, snark.Signature, tc.Signature)
+		}
+	}
+}